@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+	"strings"
 
 	"log"
 
@@ -71,3 +72,33 @@ func GetEnvLimit() int64 {
 
 	return limit
 }
+
+// GetEnvAstraStates reads the Astra state names and the states they mean,
+// given as comma-separated name=state pairs (e.g. "Scheduled=confirmed,Tentative=pending")
+func GetEnvAstraStates() map[string]string {
+	states := make(map[string]string)
+	value, exist := os.LookupEnv("ASTRA_STATES")
+	if !exist || strings.TrimSpace(value) == "" {
+		log.Printf("Warning: 'ASTRA_STATES' is not set, Astra events will have an unknown state and can't be filtered by state")
+		return states
+	}
+	for _, pair := range strings.Split(value, ",") {
+		name, state, found := strings.Cut(pair, "=")
+		if !found {
+			log.Printf("Ignoring '%s' in 'ASTRA_STATES', expected name=state", pair)
+			continue
+		}
+		states[strings.TrimSpace(name)] = strings.TrimSpace(state)
+	}
+	return states
+}
+
+// GetEnvAstraUsages reads the names of the bits of Astra usage masks, comma-separated from the lowest bit
+// with empty names for bits without one (e.g. "academic,,exam")
+func GetEnvAstraUsages() []string {
+	value, exist := os.LookupEnv("ASTRA_USAGES")
+	if !exist || strings.TrimSpace(value) == "" {
+		return nil
+	}
+	return strings.Split(value, ",")
+}
@@ -1,15 +1,11 @@
 package controllers
 
 import (
-	"context"
-	"errors"
 	"net/http"
-	"strings"
-	"time"
+	"slices"
 
 	"github.com/gin-gonic/gin"
 
-	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
 
 	"github.com/UTDNebula/nebula-api/api/configs"
@@ -25,29 +21,31 @@ var astraCollection *mongo.Collection = configs.GetCollection("astra")
 // @Description	"Returns AstraEvent based on the input date"
 // @Produce		json
 // @Param			date	path		string																true	"date (ISO format) to retrieve astra events"
+// @Param			start	query		string																false	"Only include events ending after this time (e.g. 2:00pm or 14:00)"
+// @Param			end		query		string																false	"Only include events starting before this time (e.g. 4:00pm or 16:00)"
+// @Param			state	query		string																false	"Only include bookings in these states, comma-separated (confirmed, pending, cancelled, denied or unknown). States no configured Astra state name means are rejected."
 // @Success		200		{object}	schema.APIResponse[schema.MultiBuildingEvents[schema.AstraEvent]]	"All AstraEvents with events on the inputted date"
 // @Failure		500		{object}	schema.APIResponse[string]											"A string describing the error"
+// @Failure		400		{object}	schema.APIResponse[string]											"A string describing the error"
 func AstraEvents(c *gin.Context) {
-	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
-	defer cancel()
-
-	date := c.Param("date")
-
-	var astra_events schema.MultiBuildingEvents[schema.AstraEvent]
-
-	// Find astra event given date
-	err := astraCollection.FindOne(ctx, bson.M{"date": date}).Decode(&astra_events)
-	if err != nil {
-		if errors.Is(err, mongo.ErrNoDocuments) {
-			astra_events.Date = date
-			astra_events.Buildings = []schema.SingleBuildingEvents[schema.AstraEvent]{}
-		} else {
-			respondWithInternalError(c, err)
-			return
-		}
-	}
+	respondWithDayEvents(c, astraEventSource)
+}
 
-	respond(c, http.StatusOK, "success", astra_events)
+// @Id				AstraEventsRange
+// @Router			/astra [get]
+// @Tags			Events
+// @Description	"Returns AstraEvents on each date in the range, up to 31 days"
+// @Produce		json
+// @Param			from	query		string																true	"ISO date of the first day of the range"
+// @Param			to		query		string																true	"ISO date of the last day of the range, inclusive"
+// @Param			start	query		string																false	"Only include events ending after this time (e.g. 2:00pm or 14:00)"
+// @Param			end		query		string																false	"Only include events starting before this time (e.g. 4:00pm or 16:00)"
+// @Param			state	query		string																false	"Only include bookings in these states, comma-separated (confirmed, pending, cancelled, denied or unknown). States no configured Astra state name means are rejected."
+// @Success		200		{object}	schema.APIResponse[[]schema.MultiBuildingEvents[schema.AstraEvent]]	"All AstraEvents on each date in the range, one entry per day"
+// @Failure		500		{object}	schema.APIResponse[string]											"A string describing the error"
+// @Failure		400		{object}	schema.APIResponse[string]											"A string describing the error"
+func AstraEventsRange(c *gin.Context) {
+	respondWithEventRange(c, astraEventSource)
 }
 
 // @Id				AstraEventsByBuilding
@@ -57,55 +55,15 @@ func AstraEvents(c *gin.Context) {
 // @Produce		json
 // @Param			date		path		string																true	"date (ISO format) to retrieve astra events"
 // @Param			building	path		string																true	"building abbreviation of event locations"
+// @Param			start		query		string																false	"Only include events ending after this time (e.g. 2:00pm or 14:00)"
+// @Param			end			query		string																false	"Only include events starting before this time (e.g. 4:00pm or 16:00)"
+// @Param			state		query		string																false	"Only include bookings in these states, comma-separated (confirmed, pending, cancelled, denied or unknown). States no configured Astra state name means are rejected."
 // @Success		200			{object}	schema.APIResponse[schema.SingleBuildingEvents[schema.AstraEvent]]	"All sections with meetings on the specified date in the specified building"
 // @Failure		500			{object}	schema.APIResponse[string]											"A string describing the error"
 // @Failure		404			{object}	schema.APIResponse[string]											"A string describing the error"
+// @Failure		400			{object}	schema.APIResponse[string]											"A string describing the error"
 func AstraEventsByBuilding(c *gin.Context) {
-	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
-	defer cancel()
-
-	date := c.Param("date")
-	building := strings.TrimSpace(c.Param("building")) // trimming the input
-
-	var astra_events schema.MultiBuildingEvents[schema.AstraEvent]
-	var astra_eventsByBuilding schema.SingleBuildingEvents[schema.AstraEvent]
-
-	// Find astra event given date
-	err := astraCollection.FindOne(ctx, bson.M{"date": date}).Decode(&astra_events)
-	if err != nil {
-		if errors.Is(err, mongo.ErrNoDocuments) {
-			respond(c, http.StatusNotFound, "error", "No events found for the specified date")
-			return
-		}
-		respondWithInternalError(c, err)
-		return
-	}
-
-	// case insensitive matching
-	for _, b := range astra_events.Buildings {
-		if strings.EqualFold(strings.TrimSpace(b.Building), building) {
-			astra_eventsByBuilding = b
-			break
-		}
-	}
-
-	if astra_eventsByBuilding.Building == "" {
-		// provide suggestion if not found
-		maxBuildings := min(len(astra_events.Buildings), 10)
-		var available []string
-
-		for i := range maxBuildings {
-			available = append(available, strings.TrimSpace(astra_events.Buildings[i].Building))
-		}
-		if len(astra_events.Buildings) > maxBuildings {
-			available = append(available, "(and more)")
-		}
-
-		respond(c, http.StatusNotFound, "error", "Building not found. Available: "+strings.Join(available, ", "))
-		return
-	}
-
-	respond(c, http.StatusOK, "success", astra_eventsByBuilding)
+	respondWithBuildingEvents(c, astraEventSource)
 }
 
 // @Id				AstraEventsByBuildingandRoom
@@ -116,67 +74,29 @@ func AstraEventsByBuilding(c *gin.Context) {
 // @Param			date		path		string																true	"date (ISO format) to retrieve astra events"
 // @Param			building	path		string																true	"building abbreviation of event locations"
 // @Param			room		path		string																true	"room number for event"
+// @Param			start		query		string																false	"Only include events ending after this time (e.g. 2:00pm or 14:00)"
+// @Param			end			query		string																false	"Only include events starting before this time (e.g. 4:00pm or 16:00)"
+// @Param			state		query		string																false	"Only include bookings in these states, comma-separated (confirmed, pending, cancelled, denied or unknown). States no configured Astra state name means are rejected."
 // @Success		200			{object}	schema.APIResponse[schema.SingleBuildingEvents[schema.AstraEvent]]	"All sections with meetings on the specified date in the specified building"
 // @Failure		500			{object}	schema.APIResponse[string]											"A string describing the error"
 // @Failure		404			{object}	schema.APIResponse[string]											"A string describing the error"
+// @Failure		400			{object}	schema.APIResponse[string]											"A string describing the error"
 func AstraEventsByBuildingAndRoom(c *gin.Context) {
-	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
-	defer cancel()
-
-	date := c.Param("date")
-	building := strings.TrimSpace(c.Param("building"))
-	room := strings.TrimSpace(c.Param("room"))
-
-	var astra_events schema.MultiBuildingEvents[schema.AstraEvent]
-	var roomEvents schema.RoomEvents[schema.AstraEvent]
-
-	// Find astra event given date
-	err := astraCollection.FindOne(ctx, bson.M{"date": date}).Decode(&astra_events)
-	if err != nil {
-		if errors.Is(err, mongo.ErrNoDocuments) {
-			respond(c, http.StatusNotFound, "error", "No events found for the specified date")
-			return
-		}
-		respondWithInternalError(c, err)
-		return
-	}
-
-	// matching buildings case-insensitively
-	var matchedBuilding *schema.SingleBuildingEvents[schema.AstraEvent]
-	for _, b := range astra_events.Buildings {
-		if strings.EqualFold(strings.TrimSpace(b.Building), building) {
-			matchedBuilding = &b
-			break
-		}
-	}
-
-	if matchedBuilding == nil {
-		respond(c, http.StatusNotFound, "error", "Building not found")
-		return
-	}
+	respondWithRoomEvents(c, astraEventSource)
+}
 
-	// match room case-insesitively
-	for _, r := range matchedBuilding.Rooms {
-		if strings.EqualFold(strings.TrimSpace(r.Room), room) {
-			roomEvents = r
-			break
-		}
+// astraEventFilter filters Astra events by the decoded states of the state query parameter
+func astraEventFilter(c *gin.Context) (func(event schema.AstraEvent) bool, error) {
+	value := c.Query("state")
+	if value == "" {
+		return nil, nil
 	}
-
-	if roomEvents.Room == "" {
-		maxRooms := min(len(matchedBuilding.Rooms), 20)
-		var available []string
-
-		for i := range maxRooms {
-			available = append(available, strings.TrimSpace(matchedBuilding.Rooms[i].Room))
-		}
-		if len(matchedBuilding.Rooms) > maxRooms {
-			available = append(available, "(and more)")
-		}
-
-		respond(c, http.StatusNotFound, "error", "Room not found. Available in this building: "+strings.Join(available, ", "))
-		return
+	states, err := schema.ParseAstraStates(value)
+	if err != nil {
+		respond(c, http.StatusBadRequest, "error", err.Error())
+		return nil, err
 	}
-
-	respond(c, http.StatusOK, "success", roomEvents)
+	return func(event schema.AstraEvent) bool {
+		return slices.Contains(states, event.State)
+	}, nil
 }
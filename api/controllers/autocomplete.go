@@ -1,8 +1,15 @@
 package controllers
 
 import (
+	"cmp"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"net/http"
+	"slices"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/UTDNebula/nebula-api/api/configs"
@@ -13,35 +20,317 @@ import (
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 var DAGCollection *mongo.Collection = configs.GetCollection("DAG")
 
-// @Id				autocompleteDAG
-// @Router			/autocomplete/dag [get]
+// Number of suggestions returned by default, and at most
+const (
+	defaultAutocompleteLimit = 10
+	maxAutocompleteLimit     = 50
+)
+
+// How long the autocomplete index is used before being rebuilt
+const autocompleteIndexTTL = time.Hour
+
+// Types of autocomplete suggestions, in the order they're ranked when equally popular
+const (
+	autocompleteCourse    = "course"
+	autocompleteTitle     = "title"
+	autocompleteProfessor = "professor"
+)
+
+// A suggestion in the autocomplete index, weighted by how many students took the course or professor
+type autocompleteEntry struct {
+	suggestion schema.AutocompleteSuggestion
+	weight     int
+}
+
+// A key a suggestion can be found by the prefixes of
+type autocompleteKey struct {
+	key   string
+	entry int // index of the entry
+}
+
+// An in-memory prefix index of course codes, titles and professor names, with the keys sorted so prefixes can be binary searched
+type autocompletePrefixIndex struct {
+	entries []autocompleteEntry
+	keys    []autocompleteKey
+}
+
+// The whole DAG along with a hash of it, so conditional requests can be answered without querying or encoding it,
+// and the prefix index built from it
+type autocompleteDAGSnapshot struct {
+	dag      []schema.Autocomplete
+	version  string
+	prefixes autocompletePrefixIndex
+}
+
+var autocompleteDAGs = newRefreshingIndex(autocompleteIndexTTL, buildAutocompleteDAGSnapshot)
+
+// @Id				autocomplete
+// @Router			/autocomplete [get]
 // @Tags			Other
-// @Description	"Returns an aggregation of courses for use in generating autocomplete DAGs"
+// @Description	"Returns the course codes, course titles and professor names starting with the search string, most popular first. Words of titles and names can be completed on their own (e.g. struct for Data Structures). Served from an in-memory index refreshed hourly."
 // @Produce		json
-// @Success		200	{object}	schema.APIResponse[[]schema.Autocomplete]	"An aggregation of courses for use in generating autocomplete DAGs"
-// @Failure		500	{object}	schema.APIResponse[string]					"A string describing the error"
-func AutocompleteDAG(c *gin.Context) {
+// @Param			q		query		string												true	"The start of a course code, title or professor name (e.g. cs 33)"
+// @Param			limit	query		number												false	"Maximum number of suggestions, defaults to 10, at most 50"
+// @Success		200		{object}	schema.APIResponse[[]schema.AutocompleteSuggestion]	"Suggestions, exact matches and the most popular first"
+// @Failure		500		{object}	schema.APIResponse[string]							"A string describing the error"
+// @Failure		400		{object}	schema.APIResponse[string]							"A string describing the error"
+func Autocomplete(c *gin.Context) {
 	ctx, cancel := context.WithTimeout(c.Request.Context(), 30*time.Second)
 	defer cancel()
 
-	var autocompleteDAG []schema.Autocomplete
-
-	cursor, err := DAGCollection.Find(ctx, bson.M{})
+	q := autocompleteKeyOf(c.Query("q"))
+	if q == "" {
+		respond(c, http.StatusBadRequest, "error", "q is required")
+		return
+	}
+	limit := defaultAutocompleteLimit
+	if value := c.Query("limit"); value != "" {
+		var err error
+		if limit, err = strconv.Atoi(value); err != nil || limit < 1 {
+			respond(c, http.StatusBadRequest, "error", "limit must be a positive integer")
+			return
+		}
+		limit = min(limit, maxAutocompleteLimit)
+	}
 
+	snapshot, err := autocompleteDAGs.Get(ctx)
 	if err != nil {
 		respondWithInternalError(c, err)
 		return
 	}
 
-	// Retrieve and parse all valid documents from view
-	if err = cursor.All(ctx, &autocompleteDAG); err != nil {
+	respond(c, http.StatusOK, "success", snapshot.prefixes.complete(q, limit))
+}
+
+// @Id				autocompleteDAG
+// @Router			/autocomplete/dag [get]
+// @Tags			Other
+// @Description	"Returns an aggregation of courses for use in generating autocomplete DAGs, optionally limited to some subjects and academic sessions. Served from a snapshot of the DAG refreshed hourly. Responses carry an ETag of the snapshot and filters, so clients sending it back in If-None-Match only download the DAG again once it changes."
+// @Produce		json
+// @Param			subject_prefix		query		string										false	"Subject prefixes to include, comma-separated (e.g. CS,MATH)"
+// @Param			academic_session	query		string										false	"Academic sessions to include, comma-separated (e.g. 24F,25S), leaving out courses not offered in any of them"
+// @Param			If-None-Match		header		string										false	"ETag of a previous response, to get a 304 if the DAG hasn't changed since"
+// @Success		200					{object}	schema.APIResponse[[]schema.Autocomplete]	"An aggregation of courses for use in generating autocomplete DAGs"
+// @Success		304					"The DAG hasn't changed since the response with the given ETag"
+// @Failure		500					{object}	schema.APIResponse[string]					"A string describing the error"
+func AutocompleteDAG(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 30*time.Second)
+	defer cancel()
+
+	snapshot, err := autocompleteDAGs.Get(ctx)
+	if err != nil {
 		respondWithInternalError(c, err)
 		return
 	}
 
-	respond(c, http.StatusOK, "success", autocompleteDAG)
+	prefixes := commaSeparatedQuery(c, "subject_prefix")
+	sessions := commaSeparatedQuery(c, "academic_session")
+	// The response only changes with the DAG and the filters, so a client that already has it gets a 304 without filtering anything
+	respondWithETag(c, autocompleteDAGVersion(snapshot.version, prefixes, sessions), func() []schema.Autocomplete {
+		autocompleteDAG := filterAutocompleteSubjects(snapshot.dag, prefixes)
+		if len(sessions) > 0 {
+			autocompleteDAG = filterAutocompleteSessions(autocompleteDAG, sessions)
+		}
+		return autocompleteDAG
+	})
+}
+
+// buildAutocompleteDAGSnapshot retrieves the whole DAG, hashes it into its version and builds the prefix index from it
+func buildAutocompleteDAGSnapshot(ctx context.Context) (autocompleteDAGSnapshot, error) {
+	dag := []schema.Autocomplete{}
+	// Sorted so the same DAG always has the same version
+	cursor, err := DAGCollection.Find(ctx, bson.M{}, options.Find().SetSort(bson.D{{Key: "subject_prefix", Value: 1}}))
+	if err != nil {
+		return autocompleteDAGSnapshot{}, err
+	}
+	if err = cursor.All(ctx, &dag); err != nil {
+		return autocompleteDAGSnapshot{}, err
+	}
+	encoded, err := json.Marshal(dag)
+	if err != nil {
+		return autocompleteDAGSnapshot{}, err
+	}
+	hash := sha256.Sum256(encoded)
+	return autocompleteDAGSnapshot{dag: dag, version: hex.EncodeToString(hash[:16]), prefixes: newAutocompletePrefixIndex(dag)}, nil
+}
+
+// autocompleteDAGVersion identifies the response to a DAG request by the version of the DAG and the filters, ignoring their case and order
+func autocompleteDAGVersion(version string, prefixes []string, sessions []string) string {
+	normalize := func(values []string) string {
+		values = slices.Clone(values)
+		for i, value := range values {
+			values[i] = strings.ToUpper(value)
+		}
+		slices.Sort(values)
+		return strings.Join(slices.Compact(values), ",")
+	}
+	return version + "|" + normalize(prefixes) + "|" + normalize(sessions)
+}
+
+// filterAutocompleteSubjects keeps only the given subject prefixes of the DAG, ignoring case, or all of them if none are given
+func filterAutocompleteSubjects(dag []schema.Autocomplete, prefixes []string) []schema.Autocomplete {
+	if len(prefixes) == 0 {
+		return dag
+	}
+	filtered := make([]schema.Autocomplete, 0, len(prefixes))
+	for _, subject := range dag {
+		if slices.ContainsFunc(prefixes, func(prefix string) bool {
+			return strings.EqualFold(strings.TrimSpace(subject.Subject_prefix), prefix)
+		}) {
+			filtered = append(filtered, subject)
+		}
+	}
+	return filtered
+}
+
+// newAutocompletePrefixIndex indexes the course codes, titles and professors of the DAG.
+// Codes are found with or without a space, titles and names from the start of any of their words,
+// and names also by last name first.
+func newAutocompletePrefixIndex(dag []schema.Autocomplete) autocompletePrefixIndex {
+	var index autocompletePrefixIndex
+	professors := make(map[string]int) // entry of each professor by name key
+
+	add := func(suggestion schema.AutocompleteSuggestion, weight int, keys ...string) {
+		entry := len(index.entries)
+		index.entries = append(index.entries, autocompleteEntry{suggestion: suggestion, weight: weight})
+		for _, key := range keys {
+			if key != "" {
+				index.keys = append(index.keys, autocompleteKey{key: key, entry: entry})
+			}
+		}
+	}
+	wordKeys := func(text string) []string {
+		words := strings.Fields(autocompleteKeyOf(text))
+		keys := make([]string, len(words))
+		for i := range words {
+			keys[i] = strings.Join(words[i:], " ")
+		}
+		return keys
+	}
+
+	for _, subject := range dag {
+		prefix := strings.ToUpper(strings.TrimSpace(subject.Subject_prefix))
+		for _, course := range subject.Course_numbers {
+			number := strings.TrimSpace(course.Course_number)
+			code := prefix + " " + number
+			title := strings.TrimSpace(course.Title)
+			label := strings.TrimSpace(code + " " + title)
+
+			students := 0
+			for _, session := range course.Academic_sessions {
+				for _, section := range session.Sections {
+					students += section.Total_students
+					for _, professor := range section.Professors {
+						name := strings.TrimSpace(strings.TrimSpace(professor.First_name) + " " + strings.TrimSpace(professor.Last_name))
+						key := autocompleteKeyOf(name)
+						if key == "" {
+							continue
+						}
+						if entry, ok := professors[key]; ok {
+							index.entries[entry].weight += section.Total_students
+							continue
+						}
+						professors[key] = len(index.entries)
+						last := autocompleteKeyOf(professor.Last_name)
+						add(schema.AutocompleteSuggestion{Type: autocompleteProfessor, Value: name, Label: name},
+							section.Total_students,
+							append(wordKeys(name), strings.TrimSpace(last+" "+autocompleteKeyOf(professor.First_name)))...)
+					}
+				}
+			}
+
+			add(schema.AutocompleteSuggestion{Type: autocompleteCourse, Value: code, Label: label},
+				students,
+				autocompleteKeyOf(code), autocompleteKeyOf(prefix+number))
+			if title != "" {
+				add(schema.AutocompleteSuggestion{Type: autocompleteTitle, Value: title, Label: label}, students, wordKeys(title)...)
+			}
+		}
+	}
+
+	slices.SortFunc(index.keys, func(a, b autocompleteKey) int {
+		return cmp.Or(strings.Compare(a.key, b.key), cmp.Compare(a.entry, b.entry))
+	})
+	return index
+}
+
+// complete returns the suggestions with a key starting with the normalized query, up to the limit.
+// Exact matches come first, then the most popular.
+func (index autocompletePrefixIndex) complete(q string, limit int) []schema.AutocompleteSuggestion {
+	type match struct {
+		entry int
+		exact bool
+	}
+	var matches []match
+	seen := make(map[int]int) // position in matches of each entry
+	start, _ := slices.BinarySearchFunc(index.keys, q, func(key autocompleteKey, q string) int { return strings.Compare(key.key, q) })
+	for _, key := range index.keys[start:] {
+		if !strings.HasPrefix(key.key, q) {
+			break
+		}
+		exact := key.key == q
+		if position, ok := seen[key.entry]; ok {
+			matches[position].exact = matches[position].exact || exact
+			continue
+		}
+		seen[key.entry] = len(matches)
+		matches = append(matches, match{entry: key.entry, exact: exact})
+	}
+
+	typeOrder := []string{autocompleteCourse, autocompleteTitle, autocompleteProfessor}
+	slices.SortFunc(matches, func(a, b match) int {
+		entryA, entryB := index.entries[a.entry], index.entries[b.entry]
+		exactFirst := func(m match) int {
+			if m.exact {
+				return 0
+			}
+			return 1
+		}
+		return cmp.Or(
+			cmp.Compare(exactFirst(a), exactFirst(b)),
+			cmp.Compare(entryB.weight, entryA.weight),
+			cmp.Compare(slices.Index(typeOrder, entryA.suggestion.Type), slices.Index(typeOrder, entryB.suggestion.Type)),
+			strings.Compare(entryA.suggestion.Value, entryB.suggestion.Value),
+		)
+	})
+
+	suggestions := make([]schema.AutocompleteSuggestion, 0, min(limit, len(matches)))
+	for _, match := range matches[:min(limit, len(matches))] {
+		suggestions = append(suggestions, index.entries[match.entry].suggestion)
+	}
+	return suggestions
+}
+
+// autocompleteKeyOf normalizes text into a key of the autocomplete index, lowercase and without accents or punctuation
+func autocompleteKeyOf(text string) string {
+	return strings.Join(nameKeys(text), " ")
+}
+
+// filterAutocompleteSessions keeps only the given academic sessions of each course, ignoring case,
+// leaving out courses not offered in any of them and subjects left without courses
+func filterAutocompleteSessions(dag []schema.Autocomplete, sessions []string) []schema.Autocomplete {
+	filtered := make([]schema.Autocomplete, 0, len(dag))
+	for _, subject := range dag {
+		var courses []schema.CourseNumberAcademicSessions
+		for _, course := range subject.Course_numbers {
+			course.Academic_sessions = slices.DeleteFunc(slices.Clone(course.Academic_sessions), func(session schema.AcademicSessionSections) bool {
+				return !slices.ContainsFunc(sessions, func(name string) bool {
+					return strings.EqualFold(strings.TrimSpace(session.Academic_session.Name), name)
+				})
+			})
+			if len(course.Academic_sessions) > 0 {
+				courses = append(courses, course)
+			}
+		}
+		if len(courses) > 0 {
+			subject.Course_numbers = courses
+			filtered = append(filtered, subject)
+		}
+	}
+	return filtered
 }
@@ -0,0 +1,92 @@
+package controllers
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/UTDNebula/nebula-api/api/schema"
+)
+
+func TestAutocompleteComplete(t *testing.T) {
+	section := func(students int, first string, last string) schema.SectionNumberProfessors {
+		return schema.SectionNumberProfessors{Total_students: students, Professors: []schema.SimpleProfessor{{First_name: first, Last_name: last}}}
+	}
+	course := func(number string, title string, sections ...schema.SectionNumberProfessors) schema.CourseNumberAcademicSessions {
+		return schema.CourseNumberAcademicSessions{Course_number: number, Title: title, Academic_sessions: []schema.AcademicSessionSections{{Sections: sections}}}
+	}
+	index := newAutocompletePrefixIndex([]schema.Autocomplete{
+		{Subject_prefix: "CS", Course_numbers: []schema.CourseNumberAcademicSessions{
+			course("3345", "Data Structures and Introduction to Algorithmic Analysis", section(120, "Jane", "Doe"), section(60, "José", "Pérez")),
+			course("3305", "Discrete Mathematics for Computing II", section(200, "Jane", "Doe")),
+			course("1337", "Computer Science I", section(300, "Sam", "Structure")),
+		}},
+	})
+
+	values := func(suggestions []schema.AutocompleteSuggestion) []string {
+		var values []string
+		for _, suggestion := range suggestions {
+			values = append(values, suggestion.Value)
+		}
+		return values
+	}
+
+	testCases := map[string]struct {
+		Query    string
+		Limit    int
+		Expected []string
+	}{
+		"CodePrefix":     {Query: "cs 33", Limit: 10, Expected: []string{"CS 3305", "CS 3345"}},
+		"CompactCode":    {Query: "cs3345", Limit: 10, Expected: []string{"CS 3345"}},
+		"ExactFirst":     {Query: "cs 3345", Limit: 10, Expected: []string{"CS 3345"}},
+		"TitleWord":      {Query: "struct", Limit: 10, Expected: []string{"Sam Structure", "Data Structures and Introduction to Algorithmic Analysis"}},
+		"ProfessorTotal": {Query: "doe", Limit: 10, Expected: []string{"Jane Doe"}},
+		"Accents":        {Query: "perez", Limit: 10, Expected: []string{"José Pérez"}},
+		"LastNameFirst":  {Query: "doe ja", Limit: 10, Expected: []string{"Jane Doe"}},
+		"Limit":          {Query: "cs", Limit: 2, Expected: []string{"CS 1337", "CS 3305"}},
+		"NoMatch":        {Query: "zz", Limit: 10, Expected: nil},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			if result := values(index.complete(autocompleteKeyOf(tc.Query), tc.Limit)); !slices.Equal(result, tc.Expected) {
+				t.Errorf("Expected %v, got %v", tc.Expected, result)
+			}
+		})
+	}
+}
+
+func TestFilterAutocompleteSessions(t *testing.T) {
+	session := func(name string) schema.AcademicSessionSections {
+		return schema.AcademicSessionSections{Academic_session: schema.SimpleAcademicSession{Name: name}}
+	}
+	dag := []schema.Autocomplete{
+		{Subject_prefix: "CS", Course_numbers: []schema.CourseNumberAcademicSessions{
+			{Course_number: "3345", Academic_sessions: []schema.AcademicSessionSections{session("24F"), session("25S")}},
+			{Course_number: "4485", Academic_sessions: []schema.AcademicSessionSections{session("24F")}},
+		}},
+		{Subject_prefix: "MATH", Course_numbers: []schema.CourseNumberAcademicSessions{
+			{Course_number: "2418", Academic_sessions: []schema.AcademicSessionSections{session("24F")}},
+		}},
+	}
+
+	filtered := filterAutocompleteSessions(dag, []string{"25s"})
+	if len(filtered) != 1 || len(filtered[0].Course_numbers) != 1 || filtered[0].Course_numbers[0].Course_number != "3345" {
+		t.Fatalf("Expected only CS 3345, got %v", filtered)
+	}
+	if sessions := filtered[0].Course_numbers[0].Academic_sessions; len(sessions) != 1 || sessions[0].Academic_session.Name != "25S" {
+		t.Errorf("Expected only the 25S session, got %v", sessions)
+	}
+	if len(dag[0].Course_numbers[0].Academic_sessions) != 2 {
+		t.Error("Expected the original DAG to be left as is")
+	}
+
+	if subjects := filterAutocompleteSubjects(dag, []string{"math"}); len(subjects) != 1 || subjects[0].Subject_prefix != "MATH" {
+		t.Errorf("Expected only MATH, got %v", subjects)
+	}
+	if autocompleteDAGVersion("v1", []string{"math", "CS"}, nil) != autocompleteDAGVersion("v1", []string{"cs", "MATH"}, nil) {
+		t.Error("Expected the same filters in another case and order to have the same version")
+	}
+	if autocompleteDAGVersion("v1", []string{"CS"}, nil) == autocompleteDAGVersion("v1", nil, []string{"CS"}) {
+		t.Error("Expected subject and session filters to be told apart")
+	}
+}
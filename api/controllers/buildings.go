@@ -0,0 +1,86 @@
+package controllers
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"github.com/UTDNebula/nebula-api/api/configs"
+
+	"github.com/UTDNebula/nebula-api/api/schema"
+)
+
+// Optional names and coordinates of buildings on the campus map, see schema.MapBuilding
+var mapLocationCollection *mongo.Collection = configs.GetCollection("mapLocations")
+
+// @Id				buildings
+// @Router			/buildings [get]
+// @Tags			Other
+// @Description	"Returns the buildings on campus along with their acronyms and coordinates. Every building with rooms is listed, named and located from the campus map where it's known."
+// @Produce		json
+// @Success		200	{object}	schema.APIResponse[[]schema.MapBuilding]	"All buildings on campus, ordered by acronym"
+// @Failure		500	{object}	schema.APIResponse[string]					"A string describing the error"
+func Buildings(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	buildingRooms, err := findBuildingRooms(ctx, c, "")
+	if err != nil {
+		return
+	}
+
+	var locations []schema.MapBuilding
+	cursor, err := mapLocationCollection.Find(ctx, bson.M{})
+	if err != nil {
+		respondWithInternalError(c, err)
+		return
+	}
+	if err = cursor.All(ctx, &locations); err != nil {
+		respondWithInternalError(c, err)
+		return
+	}
+
+	respond(c, http.StatusOK, "success", mapBuildings(buildingRooms, locations))
+}
+
+// mapBuildings lists the buildings with rooms along with the buildings on the campus map, once each by acronym.
+// Coordinates of the rooms collection take precedence, buildings stored at 0, 0 there aren't located.
+func mapBuildings(buildingRooms []schema.BuildingRooms, locations []schema.MapBuilding) []schema.MapBuilding {
+	byAcronym := make(map[string]schema.MapBuilding, len(locations))
+	for _, location := range locations {
+		if location.Acronym != nil && strings.TrimSpace(*location.Acronym) != "" {
+			byAcronym[strings.ToUpper(strings.TrimSpace(*location.Acronym))] = location
+		}
+	}
+
+	for _, building := range buildingRooms {
+		acronym := strings.TrimSpace(building.Building)
+		if acronym == "" {
+			continue
+		}
+		key := strings.ToUpper(acronym)
+		location := byAcronym[key]
+		location.Acronym = &acronym
+		if building.Lat != 0 || building.Lng != 0 {
+			lat, lng := building.Lat, building.Lng
+			location.Lat, location.Lng = &lat, &lng
+		}
+		byAcronym[key] = location
+	}
+
+	buildings := make([]schema.MapBuilding, 0, len(byAcronym))
+	for _, building := range byAcronym {
+		buildings = append(buildings, building)
+	}
+	slices.SortFunc(buildings, func(a, b schema.MapBuilding) int {
+		return strings.Compare(strings.ToUpper(strings.TrimSpace(*a.Acronym)), strings.ToUpper(strings.TrimSpace(*b.Acronym)))
+	})
+	return buildings
+}
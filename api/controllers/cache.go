@@ -0,0 +1,179 @@
+package controllers
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+)
+
+// ttlCache is a small concurrency-safe in-process cache whose entries expire after a fixed duration.
+// Keys often come from query parameters, so it holds at most maxEntries entries, evicting the oldest when full.
+type ttlCache[K comparable, V any] struct {
+	mu         sync.RWMutex
+	ttl        time.Duration
+	maxEntries int
+	entries    map[K]ttlCacheEntry[V]
+}
+
+type ttlCacheEntry[V any] struct {
+	value   V
+	expires time.Time
+}
+
+func newTTLCache[K comparable, V any](ttl time.Duration, maxEntries int) *ttlCache[K, V] {
+	return &ttlCache[K, V]{ttl: ttl, maxEntries: maxEntries, entries: make(map[K]ttlCacheEntry[V])}
+}
+
+// Get returns the cached value for key if it exists and hasn't expired
+func (cache *ttlCache[K, V]) Get(key K) (V, bool) {
+	cache.mu.RLock()
+	defer cache.mu.RUnlock()
+
+	entry, ok := cache.entries[key]
+	if !ok || time.Now().After(entry.expires) {
+		var zero V
+		return zero, false
+	}
+	return entry.value, true
+}
+
+// Set stores the value for key, replacing any previous value, and drops expired entries.
+// If the cache is still full the entry closest to expiring is evicted.
+func (cache *ttlCache[K, V]) Set(key K, value V) {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+
+	now := time.Now()
+	for k, entry := range cache.entries {
+		if now.After(entry.expires) {
+			delete(cache.entries, k)
+		}
+	}
+	if _, exists := cache.entries[key]; !exists && len(cache.entries) >= cache.maxEntries {
+		var oldestKey K
+		var oldest time.Time
+		for k, entry := range cache.entries {
+			if oldest.IsZero() || entry.expires.Before(oldest) {
+				oldestKey, oldest = k, entry.expires
+			}
+		}
+		delete(cache.entries, oldestKey)
+	}
+	cache.entries[key] = ttlCacheEntry[V]{value: value, expires: now.Add(cache.ttl)}
+}
+
+// refreshingIndex is an in-process index built from the database on first use.
+// Once it's older than its TTL it's rebuilt in the background, serving the previous version until the rebuild finishes.
+type refreshingIndex[V any] struct {
+	mu         sync.Mutex
+	ttl        time.Duration
+	build      func(ctx context.Context) (V, error)
+	built      time.Time // zero until first built
+	value      V
+	building   *indexBuild // the first build in progress, if any
+	refreshing bool
+}
+
+// indexBuild is a first build of an index which requests wait on until it's done
+type indexBuild struct {
+	done chan struct{}
+	err  error // set before done is closed
+}
+
+// How long a build or background rebuild can take
+const indexRefreshTimeout = time.Minute
+
+func newRefreshingIndex[V any](ttl time.Duration, build func(ctx context.Context) (V, error)) *refreshingIndex[V] {
+	return &refreshingIndex[V]{ttl: ttl, build: build}
+}
+
+// Get returns the index, waiting for it to be built first if it's never been built or until ctx is done.
+// An expired index is returned as is while it's rebuilt in the background.
+func (index *refreshingIndex[V]) Get(ctx context.Context) (V, error) {
+	index.mu.Lock()
+	if !index.built.IsZero() {
+		if time.Since(index.built) >= index.ttl && !index.refreshing {
+			index.refreshing = true
+			go index.refresh()
+		}
+		value := index.value
+		index.mu.Unlock()
+		return value, nil
+	}
+	// Every request waiting for the first build shares it, and the next request after a failed one starts another
+	build := index.building
+	if build == nil {
+		build = &indexBuild{done: make(chan struct{})}
+		index.building = build
+		go index.buildFirst(build)
+	}
+	index.mu.Unlock()
+
+	var zero V
+	select {
+	case <-build.done:
+	case <-ctx.Done():
+		return zero, ctx.Err()
+	}
+	if build.err != nil {
+		return zero, build.err
+	}
+	index.mu.Lock()
+	defer index.mu.Unlock()
+	return index.value, nil
+}
+
+// buildFirst builds the index for the first time, independently of the requests waiting for it
+func (index *refreshingIndex[V]) buildFirst(build *indexBuild) {
+	ctx, cancel := context.WithTimeout(context.Background(), indexRefreshTimeout)
+	defer cancel()
+
+	value, err := index.build(ctx)
+
+	index.mu.Lock()
+	if err == nil {
+		index.value, index.built = value, time.Now()
+	}
+	index.building = nil
+	build.err = err
+	index.mu.Unlock()
+	close(build.done)
+}
+
+// Warm builds the index in the background so the first request doesn't have to wait for it
+func (index *refreshingIndex[V]) Warm() {
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), indexRefreshTimeout)
+		defer cancel()
+		if _, err := index.Get(ctx); err != nil {
+			log.Printf("Unable to build index: %s", err.Error())
+		}
+	}()
+}
+
+// refresh rebuilds the index without blocking readers, keeping the previous version if it fails
+func (index *refreshingIndex[V]) refresh() {
+	ctx, cancel := context.WithTimeout(context.Background(), indexRefreshTimeout)
+	defer cancel()
+
+	value, err := index.build(ctx)
+
+	index.mu.Lock()
+	defer index.mu.Unlock()
+	index.refreshing = false
+	if err != nil {
+		log.Printf("Unable to rebuild index, keeping the previous one: %s", err.Error())
+		return
+	}
+	index.value, index.built = value, time.Now()
+}
+
+// WarmIndexes builds the in-process indexes that are slow to build in the background, so they're ready by the first request
+func WarmIndexes() {
+	autocompleteDAGs.Warm()
+	professorIndex.Warm()
+	courseIndex.Warm()
+	atlasCourseSearch.Warm()
+	similarCourses.Warm()
+}
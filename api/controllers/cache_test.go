@@ -0,0 +1,74 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestTTLCacheMaxEntries(t *testing.T) {
+	cache := newTTLCache[string, int](time.Hour, 2)
+	cache.Set("a", 1)
+	time.Sleep(time.Millisecond)
+	cache.Set("b", 2)
+	cache.Set("b", 3)
+	if _, ok := cache.Get("a"); !ok {
+		t.Errorf("Expected replacing an entry not to evict another")
+	}
+
+	cache.Set("c", 4)
+	if _, ok := cache.Get("a"); ok {
+		t.Errorf("Expected the oldest entry to be evicted once full")
+	}
+	if value, ok := cache.Get("b"); !ok || value != 3 {
+		t.Errorf("Expected b to be kept with value 3, got %d", value)
+	}
+	if value, ok := cache.Get("c"); !ok || value != 4 {
+		t.Errorf("Expected c to be stored, got %d", value)
+	}
+}
+
+func TestRefreshingIndexFirstBuild(t *testing.T) {
+	release := make(chan struct{})
+	builds := 0
+	index := newRefreshingIndex(time.Hour, func(ctx context.Context) (int, error) {
+		builds++
+		<-release
+		if builds == 1 {
+			return 0, errors.New("unavailable")
+		}
+		return 42, nil
+	})
+
+	// A request stops waiting when its own context is done, even though the build is still going
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := index.Get(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected the canceled request to stop waiting, got %v", err)
+	}
+
+	// Every request waiting for the failed build gets its error
+	errs := make(chan error, 2)
+	for range 2 {
+		go func() {
+			_, err := index.Get(context.Background())
+			errs <- err
+		}()
+	}
+	time.Sleep(10 * time.Millisecond)
+	close(release)
+	for range 2 {
+		if err := <-errs; err == nil {
+			t.Error("Expected the failed build's error")
+		}
+	}
+
+	// The next request builds it again
+	if value, err := index.Get(context.Background()); err != nil || value != 42 {
+		t.Errorf("Expected 42 from the second build, got %d, %v", value, err)
+	}
+	if builds != 2 {
+		t.Errorf("Expected 2 builds, got %d", builds)
+	}
+}
@@ -3,53 +3,65 @@ package controllers
 import (
 	"context"
 	"errors"
-	"log"
 	"net/http"
-	"net/url"
+	"regexp"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
 
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
 
 	"github.com/UTDNebula/nebula-api/api/configs"
-
 	"github.com/UTDNebula/nebula-api/api/schema"
 )
 
 var cometCalendarCollection *mongo.Collection = configs.GetCollection("cometCalendar")
 
+// Query parameters of the calendar search by the array field of the event they filter
+var calendarSearchArrays = []struct {
+	param string
+	field string
+}{
+	{param: "event_type", field: "event_type"},
+	{param: "target_audience", field: "target_audience"},
+	{param: "topic", field: "topic"},
+	{param: "event_tags", field: "event_tags"},
+	{param: "department", field: "department"},
+}
+
 // @Id				CometCalendarEvents
 // @Router			/calendar/{date} [get]
 // @Tags			Events
 // @Description	"Returns CometCalendarEvent based on the input date"
 // @Produce		json
 // @Param			date	path		string															true	"date (ISO format) to retrieve comet calendar events"
+// @Param			start	query		string															false	"Only include events ending after this time (e.g. 2:00pm or 14:00)"
+// @Param			end		query		string															false	"Only include events starting before this time (e.g. 4:00pm or 16:00)"
 // @Success		200		{object}	schema.APIResponse[schema.MultiBuildingEvents[schema.Event]]	"All CometCalendarEvents with events on the inputted date"
 // @Failure		500		{object}	schema.APIResponse[string]										"A string describing the error"
+// @Failure		400		{object}	schema.APIResponse[string]										"A string describing the error"
 func CometCalendarEvents(c *gin.Context) {
-	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
-	defer cancel()
-
-	date := c.Param("date")
-
-	var cometCalendarEvents schema.MultiBuildingEvents[schema.Event]
-
-	// Find comet calendar event given date
-	err := cometCalendarCollection.FindOne(ctx, bson.M{"date": date}).Decode(&cometCalendarEvents)
-	if err != nil {
-		if errors.Is(err, mongo.ErrNoDocuments) {
-			cometCalendarEvents.Date = date
-			cometCalendarEvents.Buildings = []schema.SingleBuildingEvents[schema.Event]{}
-		} else {
-			respondWithInternalError(c, err)
-			return
-		}
-	}
+	respondWithDayEvents(c, cometCalendarEventSource)
+}
 
-	respond(c, http.StatusOK, "success", cometCalendarEvents)
+// @Id				CometCalendarEventsRange
+// @Router			/calendar [get]
+// @Tags			Events
+// @Description	"Returns CometCalendarEvents on each date in the range, up to 31 days"
+// @Produce		json
+// @Param			from	query		string															true	"ISO date of the first day of the range"
+// @Param			to		query		string															true	"ISO date of the last day of the range, inclusive"
+// @Param			start	query		string															false	"Only include events ending after this time (e.g. 2:00pm or 14:00)"
+// @Param			end		query		string															false	"Only include events starting before this time (e.g. 4:00pm or 16:00)"
+// @Success		200		{object}	schema.APIResponse[[]schema.MultiBuildingEvents[schema.Event]]	"All CometCalendarEvents on each date in the range, one entry per day"
+// @Failure		500		{object}	schema.APIResponse[string]										"A string describing the error"
+// @Failure		400		{object}	schema.APIResponse[string]										"A string describing the error"
+func CometCalendarEventsRange(c *gin.Context) {
+	respondWithEventRange(c, cometCalendarEventSource)
 }
 
 // @Id				CometCalendarEventsByBuilding
@@ -59,68 +71,14 @@ func CometCalendarEvents(c *gin.Context) {
 // @Produce		json
 // @Param			date		path		string															true	"date (ISO format) to retrieve comet calendar events"
 // @Param			building	path		string															true	"building abbreviation of event locations"
+// @Param			start		query		string															false	"Only include events ending after this time (e.g. 2:00pm or 14:00)"
+// @Param			end			query		string															false	"Only include events starting before this time (e.g. 4:00pm or 16:00)"
 // @Success		200			{object}	schema.APIResponse[schema.SingleBuildingEvents[schema.Event]]	"All events on the specified date in the specified building"
 // @Failure		500			{object}	schema.APIResponse[string]										"A string describing the error"
 // @Failure		404			{object}	schema.APIResponse[string]										"A string describing the error"
+// @Failure		400			{object}	schema.APIResponse[string]										"A string describing the error"
 func CometCalendarEventsByBuilding(c *gin.Context) {
-	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
-	defer cancel()
-
-	date := c.Param("date")
-
-	// URL decode the building parameter in case it contains special characters
-	// Use PathUnescape for path parameters (not QueryUnescape)
-	buildingParam, err := url.PathUnescape(c.Param("building"))
-	if err != nil {
-		buildingParam = c.Param("building")
-	}
-	building := strings.TrimSpace(buildingParam)
-
-	var cometCalendarEvents schema.MultiBuildingEvents[schema.Event]
-	var cometCalendarEventsByBuilding schema.SingleBuildingEvents[schema.Event]
-
-	// Find comet calendar event given date
-	err = cometCalendarCollection.FindOne(ctx, bson.M{"date": date}).Decode(&cometCalendarEvents)
-	if err != nil {
-		if errors.Is(err, mongo.ErrNoDocuments) {
-			respond(c, http.StatusNotFound, "error", "No events found for the specified date")
-			return
-		} else {
-			respondWithInternalError(c, err)
-			return
-		}
-	}
-
-	// Check if any buildings exist
-	if len(cometCalendarEvents.Buildings) == 0 {
-		respond(c, http.StatusNotFound, "error", "No buildings found for the specified date")
-		return
-	}
-
-	// Parse response for requested building (case-insensitive matching)
-	for _, b := range cometCalendarEvents.Buildings {
-		if strings.EqualFold(strings.TrimSpace(b.Building), building) {
-			cometCalendarEventsByBuilding = b
-			break
-		}
-	}
-
-	if cometCalendarEventsByBuilding.Building == "" {
-		maxBuildings := min(len(cometCalendarEvents.Buildings), 10)
-		var available []string
-
-		for i := range maxBuildings {
-			available = append(available, strings.TrimSpace(cometCalendarEvents.Buildings[i].Building))
-		}
-		if len(cometCalendarEvents.Buildings) > maxBuildings {
-			available = append(available, "(and more)")
-		}
-
-		respond(c, http.StatusNotFound, "error", "Building not found. Available: "+strings.Join(available, ", "))
-		return
-	}
-
-	respond(c, http.StatusOK, "success", cometCalendarEventsByBuilding)
+	respondWithBuildingEvents(c, cometCalendarEventSource)
 }
 
 // @Id				CometCalendarEventsByBuildingAndRoom
@@ -131,106 +89,164 @@ func CometCalendarEventsByBuilding(c *gin.Context) {
 // @Param			date		path		string												true	"date (ISO format) to retrieve comet calendar events"
 // @Param			building	path		string												true	"building abbreviation of event locations"
 // @Param			room		path		string												true	"room number for event"
+// @Param			start		query		string												false	"Only include events ending after this time (e.g. 2:00pm or 14:00)"
+// @Param			end			query		string												false	"Only include events starting before this time (e.g. 4:00pm or 16:00)"
 // @Success		200			{object}	schema.APIResponse[schema.RoomEvents[schema.Event]]	"All events on the specified date in the specified building and room"
 // @Failure		500			{object}	schema.APIResponse[string]							"A string describing the error"
 // @Failure		404			{object}	schema.APIResponse[string]							"A string describing the error"
+// @Failure		400			{object}	schema.APIResponse[string]							"A string describing the error"
 func CometCalendarEventsByBuildingAndRoom(c *gin.Context) {
+	respondWithRoomEvents(c, cometCalendarEventSource)
+}
+
+// @Id				CometCalendarSearch
+// @Router			/calendar/search [get]
+// @Tags			Events
+// @Description	"Returns the CometCalendarEvents matching all of the given filters, soonest first, each listed once however many days it spans. Array filters take comma-separated values and match events having any of them, ignoring case."
+// @Produce		json
+// @Param			q				query		string								false	"Text to find in the summary or description, ignoring case"
+// @Param			event_type		query		string								false	"Event types to include, comma-separated"
+// @Param			target_audience	query		string								false	"Target audiences to include, comma-separated"
+// @Param			topic			query		string								false	"Topics to include, comma-separated"
+// @Param			event_tags		query		string								false	"Event tags to include, comma-separated"
+// @Param			department		query		string								false	"Departments to include, comma-separated"
+// @Param			from			query		string								false	"ISO date of the first day events can take place on, defaults to today"
+// @Param			to				query		string								false	"ISO date of the last day events can take place on, inclusive"
+// @Param			offset			query		number								false	"The starting position of the current page of events (e.g. For starting at the 17th event, offset=16)."
+// @Success		200				{object}	schema.APIResponse[[]schema.Event]	"A page of matching events"
+// @Failure		500				{object}	schema.APIResponse[string]			"A string describing the error"
+// @Failure		400				{object}	schema.APIResponse[string]			"A string describing the error"
+func CometCalendarSearch(c *gin.Context) {
 	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
 	defer cancel()
 
-	date := c.Param("date")
-
-	// URL decode the building and room parameters in case they contain special characters
-	// Use PathUnescape for path parameters (not QueryUnescape)
-	buildingParam, err := url.PathUnescape(c.Param("building"))
-	if err != nil {
-		buildingParam = c.Param("building")
-	}
-	building := strings.TrimSpace(buildingParam)
-
-	roomParam, err := url.PathUnescape(c.Param("room"))
+	from, to, err := calendarSearchRange(c, campusToday())
 	if err != nil {
-		roomParam = c.Param("room")
+		return
 	}
-	room := strings.TrimSpace(roomParam)
 
-	var cometCalendarEvents schema.MultiBuildingEvents[schema.Event]
-	var roomEvents schema.RoomEvents[schema.Event]
-
-	// Find comet calendar event given date
-	err = cometCalendarCollection.FindOne(ctx, bson.M{"date": date}).Decode(&cometCalendarEvents)
-	if err != nil {
-		if errors.Is(err, mongo.ErrNoDocuments) {
-			respond(c, http.StatusNotFound, "error", "No events found for the specified date")
-			return
-		} else {
-			respondWithInternalError(c, err)
+	var offset int64
+	if value := c.Query("offset"); value != "" {
+		if offset, err = strconv.ParseInt(value, 10, 64); err != nil || offset < 0 {
+			respond(c, http.StatusBadRequest, "offset is not a non-negative integer", value)
 			return
 		}
 	}
 
-	// Check if any buildings exist
-	if len(cometCalendarEvents.Buildings) == 0 {
-		respond(c, http.StatusNotFound, "error", "No buildings found for the specified date")
+	events, err := findCalendarSearchEvents(ctx, c, from, to, offset, configs.GetEnvLimit())
+	if err != nil {
 		return
 	}
 
-	// Parse response for requested building and room (case-insensitive matching)
-	buildingFound := false
-	var matchedBuilding *schema.SingleBuildingEvents[schema.Event]
-
-	for _, b := range cometCalendarEvents.Buildings {
-		buildingName := strings.TrimSpace(b.Building)
+	respond(c, http.StatusOK, "success", events)
+}
 
-		if strings.EqualFold(buildingName, building) {
-			buildingFound = true
-			matchedBuilding = &b
-			break
+// calendarSearchRange parses the from and to query parameters of the calendar search, from defaulting to the given day and to being nil if not given.
+// Automatically responds with an error if either isn't an ISO date or the range is empty.
+func calendarSearchRange(c *gin.Context, from time.Time) (time.Time, *time.Time, error) {
+	if value := c.Query("from"); value != "" {
+		var err error
+		if from, err = parseEventDate(value); err != nil {
+			respond(c, http.StatusBadRequest, "error", "from: "+err.Error())
+			return from, nil, err
 		}
 	}
-
-	if !buildingFound {
-		respond(c, http.StatusNotFound, "error", "Building not found")
-		return
+	if value := c.Query("to"); value != "" {
+		to, err := parseEventDate(value)
+		if err != nil {
+			respond(c, http.StatusBadRequest, "error", "to: "+err.Error())
+			return from, nil, err
+		}
+		if to.Before(from) {
+			err = errors.New("to must not be before from")
+			respond(c, http.StatusBadRequest, "error", err.Error())
+			return from, nil, err
+		}
+		return from, &to, nil
 	}
+	return from, nil, nil
+}
 
-	// Check if any rooms exist for this building
-	if len(matchedBuilding.Rooms) == 0 {
-		respond(c, http.StatusNotFound, "error", "No rooms found for the specified building")
-		return
+// findCalendarSearchEvents retrieves a page of the events matching the search query parameters within the range.
+// Automatically responds with an error if they can't be retrieved.
+func findCalendarSearchEvents(ctx context.Context, c *gin.Context, from time.Time, to *time.Time, offset int64, limit int64) ([]schema.Event, error) {
+	pipeline := calendarSearchPipeline(calendarSearchFilter(c.Request.URL.Query(), from, to), from, to)
+	pipeline = append(pipeline,
+		bson.D{{Key: "$skip", Value: offset}},
+		bson.D{{Key: "$limit", Value: limit}},
+	)
+
+	events := []schema.Event{}
+	cursor, err := cometCalendarCollection.Aggregate(ctx, pipeline)
+	if err != nil {
+		respondWithInternalError(c, err)
+		return nil, err
+	}
+	if err = cursor.All(ctx, &events); err != nil {
+		respondWithInternalError(c, err)
+		return nil, err
 	}
+	return events, nil
+}
 
-	// Look for the room - try exact match first, then case-insensitive
-	for _, r := range matchedBuilding.Rooms {
-		roomName := strings.TrimSpace(r.Room)
+// calendarSearchFilter builds the filter of individual events from the search query parameters and date range.
+// Events overlapping the range match, to being nil leaves it open-ended.
+func calendarSearchFilter(query map[string][]string, from time.Time, to *time.Time) bson.D {
+	filter := bson.D{{Key: "end_time", Value: bson.M{"$gt": from}}}
+	if to != nil {
+		filter = append(filter, bson.E{Key: "start_time", Value: bson.M{"$lt": to.AddDate(0, 0, 1)}})
+	}
 
-		// Try exact match first
-		if roomName == room {
-			log.Printf("Found exact match for room: %s", room)
-			roomEvents = r
-			break
+	for _, array := range calendarSearchArrays {
+		var values bson.A
+		for _, param := range query[array.param] {
+			for _, value := range strings.Split(param, ",") {
+				if value = strings.TrimSpace(value); value != "" {
+					values = append(values, primitive.Regex{Pattern: "^" + regexp.QuoteMeta(value) + "$", Options: "i"})
+				}
+			}
 		}
-		// Try case-insensitive match
-		if strings.EqualFold(roomName, room) {
-			roomEvents = r
-			break
+		if len(values) > 0 {
+			filter = append(filter, bson.E{Key: array.field, Value: bson.M{"$in": values}})
 		}
 	}
 
-	if roomEvents.Room == "" {
-		// Collect available rooms only when needed (limit to first 20 for performance)
-		maxRooms := min(len(matchedBuilding.Rooms), 20)
-		availableRooms := make([]string, 0, maxRooms)
-		for i := range maxRooms {
-			availableRooms = append(availableRooms, strings.TrimSpace(matchedBuilding.Rooms[i].Room))
-		}
-		roomList := strings.Join(availableRooms, ", ")
-		if len(matchedBuilding.Rooms) > 20 {
-			roomList += " (and more...)"
-		}
-		respond(c, http.StatusNotFound, "error", "No events found for the specified room. Available rooms: "+roomList)
-		return
+	if text := strings.TrimSpace(firstValue(query["q"])); text != "" {
+		pattern := bson.D{{Key: "$regex", Value: regexp.QuoteMeta(text)}, {Key: "$options", Value: "i"}}
+		filter = append(filter, bson.E{Key: "$or", Value: bson.A{
+			bson.M{"summary": pattern},
+			bson.M{"description": pattern},
+		}})
+	}
+	return filter
+}
+
+// calendarSearchPipeline flattens the events of the days in the range out of the event hierarchy,
+// keeping those matching the filter once each, soonest first
+func calendarSearchPipeline(filter bson.D, from time.Time, to *time.Time) mongo.Pipeline {
+	// Multi-day events are stored under each of their days, which is why they're grouped back together
+	dates := bson.M{"$gte": from.Format(time.DateOnly)}
+	if to != nil {
+		dates["$lte"] = to.Format(time.DateOnly)
 	}
 
-	respond(c, http.StatusOK, "success", roomEvents)
+	return mongo.Pipeline{
+		bson.D{{Key: "$match", Value: bson.M{"date": dates}}},
+		bson.D{{Key: "$unwind", Value: "$buildings"}},
+		bson.D{{Key: "$unwind", Value: "$buildings.rooms"}},
+		bson.D{{Key: "$unwind", Value: "$buildings.rooms.events"}},
+		bson.D{{Key: "$replaceRoot", Value: bson.M{"newRoot": "$buildings.rooms.events"}}},
+		bson.D{{Key: "$match", Value: filter}},
+		bson.D{{Key: "$group", Value: bson.M{"_id": "$_id", "event": bson.M{"$first": "$$ROOT"}}}},
+		bson.D{{Key: "$replaceRoot", Value: bson.M{"newRoot": "$event"}}},
+		bson.D{{Key: "$sort", Value: bson.D{{Key: "start_time", Value: 1}, {Key: "_id", Value: 1}}}},
+	}
+}
+
+// firstValue returns the first of the values of a query parameter, or an empty string if there are none
+func firstValue(values []string) string {
+	if len(values) == 0 {
+		return ""
+	}
+	return values[0]
 }
@@ -0,0 +1,238 @@
+package controllers
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/UTDNebula/nebula-api/api/schema"
+)
+
+// Maximum number of events included in a calendar feed
+const maxFeedEvents = 500
+
+// How far back feeds include events by default, so that recent events don't vanish from subscribed calendars
+const feedLookbackDays = 30
+
+// Identifies the API as the producer of its calendar feeds, and the domain UIDs are scoped to
+const (
+	icalProductID = "-//UTD Nebula//Nebula API//EN"
+	icalUIDDomain = "api.utdnebula.com"
+)
+
+// iCalendar lines are folded at 75 octets, not counting the line break
+const icalLineOctets = 75
+
+// @Id				CometCalendarFeed
+// @Router			/calendar/feed.ics [get]
+// @Tags			Events
+// @Description	"Returns the CometCalendarEvents matching the same filters as the calendar search as an iCalendar (RFC 5545) feed that calendar apps can subscribe to. Event UIDs are derived from their IDs so they stay stable between refreshes."
+// @Produce		text/calendar
+// @Param			q				query		string						false	"Text to find in the summary or description, ignoring case"
+// @Param			event_type		query		string						false	"Event types to include, comma-separated"
+// @Param			target_audience	query		string						false	"Target audiences to include, comma-separated"
+// @Param			topic			query		string						false	"Topics to include, comma-separated"
+// @Param			event_tags		query		string						false	"Event tags to include, comma-separated"
+// @Param			department		query		string						false	"Departments to include, comma-separated"
+// @Param			from			query		string						false	"ISO date of the first day events can take place on, defaults to 30 days ago"
+// @Param			to				query		string						false	"ISO date of the last day events can take place on, inclusive"
+// @Success		200				{string}	string						"An iCalendar feed of up to 500 matching events, upcoming ones first and then the most recent past ones"
+// @Failure		500				{object}	schema.APIResponse[string]	"A string describing the error"
+// @Failure		400				{object}	schema.APIResponse[string]	"A string describing the error"
+func CometCalendarFeed(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	from, to, err := calendarSearchRange(c, campusToday().AddDate(0, 0, -feedLookbackDays))
+	if err != nil {
+		return
+	}
+
+	events, err := findFeedEvents(ctx, c, from, to)
+	if err != nil {
+		return
+	}
+
+	c.Header("Content-Disposition", `inline; filename="comet-calendar.ics"`)
+	c.Data(http.StatusOK, "text/calendar; charset=utf-8", []byte(icalFeed(events, time.Now())))
+}
+
+// findFeedEvents retrieves the events matching the search query parameters within the range, up to maxFeedEvents.
+// Subscribers care about what's coming up, so upcoming events are kept first and the most recent past events fill in the rest.
+// Automatically responds with an error if they can't be retrieved.
+func findFeedEvents(ctx context.Context, c *gin.Context, from time.Time, to *time.Time) ([]schema.Event, error) {
+	today := campusToday()
+	events := []schema.Event{}
+	if to == nil || !to.Before(today) {
+		upcomingFrom := from
+		if upcomingFrom.Before(today) {
+			upcomingFrom = today
+		}
+		var err error
+		if events, err = findCalendarSearchEvents(ctx, c, upcomingFrom, to, 0, maxFeedEvents); err != nil {
+			return nil, err
+		}
+	}
+	if len(events) >= maxFeedEvents || !from.Before(today) {
+		return events, nil
+	}
+
+	pastTo := today.AddDate(0, 0, -1)
+	if to != nil && to.Before(pastTo) {
+		pastTo = *to
+	}
+	pipeline := calendarSearchPipeline(calendarSearchFilter(c.Request.URL.Query(), from, &pastTo), from, &pastTo)
+	pipeline = append(pipeline,
+		bson.D{{Key: "$sort", Value: bson.D{{Key: "start_time", Value: -1}, {Key: "_id", Value: -1}}}},
+		bson.D{{Key: "$limit", Value: maxFeedEvents - len(events)}},
+	)
+	var past []schema.Event
+	cursor, err := cometCalendarCollection.Aggregate(ctx, pipeline)
+	if err != nil {
+		respondWithInternalError(c, err)
+		return nil, err
+	}
+	if err = cursor.All(ctx, &past); err != nil {
+		respondWithInternalError(c, err)
+		return nil, err
+	}
+	return mergeFeedEvents(past, events), nil
+}
+
+// mergeFeedEvents puts the past events, given most recent first, back in order before the upcoming ones,
+// leaving out the events in both because they were still going on today
+func mergeFeedEvents(past []schema.Event, upcoming []schema.Event) []schema.Event {
+	upcomingIds := make(map[primitive.ObjectID]bool, len(upcoming))
+	for _, event := range upcoming {
+		upcomingIds[event.Id] = true
+	}
+	past = slices.DeleteFunc(past, func(event schema.Event) bool { return upcomingIds[event.Id] })
+	slices.Reverse(past)
+	return append(past, upcoming...)
+}
+
+// icalFeed encodes events as an iCalendar, stamped with the time it was generated
+func icalFeed(events []schema.Event, stamp time.Time) string {
+	var feed strings.Builder
+	writeLine := func(name string, value string) {
+		feed.WriteString(foldICalLine(name + ":" + value))
+	}
+
+	writeLine("BEGIN", "VCALENDAR")
+	writeLine("VERSION", "2.0")
+	writeLine("PRODID", icalProductID)
+	writeLine("CALSCALE", "GREGORIAN")
+	writeLine("METHOD", "PUBLISH")
+	writeLine("X-WR-CALNAME", "Comet Calendar")
+	for _, event := range events {
+		if event.StartTime.IsZero() {
+			continue
+		}
+		writeLine("BEGIN", "VEVENT")
+		writeLine("UID", event.Id.Hex()+"@"+icalUIDDomain)
+		writeLine("DTSTAMP", icalTime(stamp))
+		writeLine("DTSTART", icalTime(event.StartTime))
+		if event.EndTime.After(event.StartTime) {
+			writeLine("DTEND", icalTime(event.EndTime))
+		}
+		writeLine("SUMMARY", escapeICalText(event.Summary))
+		if description := icalDescription(event); description != "" {
+			writeLine("DESCRIPTION", escapeICalText(description))
+		}
+		if location := strings.TrimSpace(event.Location); location != "" {
+			writeLine("LOCATION", escapeICalText(location))
+		}
+		if website := strings.TrimSpace(event.EventWebsite); website != "" {
+			writeLine("URL", website)
+		}
+		if email := strings.TrimSpace(event.ContactEmail); email != "" {
+			organizer := "ORGANIZER"
+			if name := strings.TrimSpace(event.ContactName); name != "" {
+				organizer += `;CN="` + strings.ReplaceAll(name, `"`, "'") + `"`
+			}
+			writeLine(organizer, "mailto:"+email)
+		}
+		if categories := icalCategories(event); categories != "" {
+			writeLine("CATEGORIES", categories)
+		}
+		writeLine("END", "VEVENT")
+	}
+	writeLine("END", "VCALENDAR")
+	return feed.String()
+}
+
+// icalTime formats a time as an iCalendar UTC date-time
+func icalTime(t time.Time) string {
+	return t.UTC().Format("20060102T150405Z")
+}
+
+// icalDescription is the description of an event followed by whichever of its contact details aren't already in the feed
+func icalDescription(event schema.Event) string {
+	lines := []string{strings.TrimSpace(event.Description)}
+	contact := strings.TrimSpace(event.ContactName)
+	if strings.TrimSpace(event.ContactEmail) != "" {
+		contact = "" // the name is given with the organizer
+	}
+	if phone := strings.TrimSpace(event.ContactPhoneNumber); phone != "" {
+		contact = strings.TrimSpace(contact + " " + phone)
+	}
+	if contact != "" {
+		lines = append(lines, "Contact: "+contact)
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n\n"))
+}
+
+// icalCategories lists the event types and topics of an event as escaped iCalendar categories
+func icalCategories(event schema.Event) string {
+	var categories []string
+	for _, category := range append(append([]string{}, event.EventType...), event.Topic...) {
+		if category = strings.TrimSpace(category); category != "" {
+			categories = append(categories, escapeICalText(category))
+		}
+	}
+	return strings.Join(categories, ",")
+}
+
+// escapeICalText escapes backslashes, semicolons, commas and line breaks in iCalendar text values
+func escapeICalText(text string) string {
+	return strings.NewReplacer(
+		`\`, `\\`,
+		";", `\;`,
+		",", `\,`,
+		"\r\n", `\n`,
+		"\n", `\n`,
+		"\r", `\n`,
+	).Replace(text)
+}
+
+// foldICalLine ends a content line with CRLF, breaking it into continuation lines beginning with a space
+// so that no line exceeds 75 octets, without splitting UTF-8 characters. Invalid UTF-8 is replaced first.
+func foldICalLine(line string) string {
+	line = strings.ToValidUTF8(line, string(utf8.RuneError))
+
+	var folded strings.Builder
+	limit := icalLineOctets
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+		if cut == 0 {
+			// Only possible with invalid UTF-8, cut anywhere rather than never progressing
+			cut = limit
+		}
+		folded.WriteString(line[:cut] + "\r\n ")
+		line = line[cut:]
+		limit = icalLineOctets - 1 // the leading space counts
+	}
+	folded.WriteString(line + "\r\n")
+	return folded.String()
+}
@@ -0,0 +1,81 @@
+package controllers
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/UTDNebula/nebula-api/api/schema"
+)
+
+func TestMergeFeedEvents(t *testing.T) {
+	event := func(summary string) schema.Event {
+		return schema.Event{Id: primitive.NewObjectID(), Summary: summary}
+	}
+	lastWeek, yesterday, ongoing, tomorrow := event("last week"), event("yesterday"), event("ongoing"), event("tomorrow")
+
+	merged := mergeFeedEvents([]schema.Event{ongoing, yesterday, lastWeek}, []schema.Event{ongoing, tomorrow})
+	var summaries []string
+	for _, event := range merged {
+		summaries = append(summaries, event.Summary)
+	}
+	if expected := []string{"last week", "yesterday", "ongoing", "tomorrow"}; !slices.Equal(summaries, expected) {
+		t.Errorf("Expected %v, got %v", expected, summaries)
+	}
+}
+
+func TestICalFeed(t *testing.T) {
+	id := primitive.NewObjectID()
+	event := schema.Event{
+		Id:           id,
+		Summary:      "Resume Review; Bring a copy, please",
+		Location:     "SSB 3.300",
+		StartTime:    time.Date(2025, time.September, 2, 14, 0, 0, 0, campusLocation),
+		EndTime:      time.Date(2025, time.September, 2, 15, 30, 0, 0, campusLocation),
+		Description:  "Line one\nLine two",
+		EventWebsite: "https://calendar.utdallas.edu/event/resume-review",
+		EventType:    []string{"Workshop"},
+		Topic:        []string{"Career"},
+		ContactName:  "Career Center",
+		ContactEmail: "careers@utdallas.edu",
+	}
+	feed := icalFeed([]schema.Event{event}, time.Date(2025, time.August, 1, 0, 0, 0, 0, time.UTC))
+
+	for _, line := range []string{
+		"BEGIN:VCALENDAR\r\n",
+		"UID:" + id.Hex() + "@" + icalUIDDomain + "\r\n",
+		"DTSTART:20250902T190000Z\r\n",
+		"DTEND:20250902T203000Z\r\n",
+		`SUMMARY:Resume Review\; Bring a copy\, please` + "\r\n",
+		`DESCRIPTION:Line one\nLine two` + "\r\n",
+		`ORGANIZER;CN="Career Center":mailto:careers@utdallas.edu` + "\r\n",
+		"CATEGORIES:Workshop,Career\r\n",
+		"END:VCALENDAR\r\n",
+	} {
+		if !strings.Contains(feed, line) {
+			t.Errorf("Expected the feed to contain %q, got %q", line, feed)
+		}
+	}
+
+	long := "DESCRIPTION:" + strings.Repeat("é", 60)
+	folded := foldICalLine(long)
+	for _, line := range strings.Split(strings.TrimSuffix(folded, "\r\n"), "\r\n") {
+		if len(line) > icalLineOctets || !utf8.ValidString(strings.TrimPrefix(line, " ")) {
+			t.Errorf("Expected lines of at most %d octets of whole characters, got %q", icalLineOctets, line)
+		}
+	}
+	if unfolded := strings.ReplaceAll(folded, "\r\n ", ""); unfolded != long+"\r\n" {
+		t.Errorf("Expected unfolding to restore the line, got %q", unfolded)
+	}
+	// A long run of continuation bytes has no character boundary to fold at
+	invalid := foldICalLine("DESCRIPTION:" + strings.Repeat("\x80", 200))
+	for _, line := range strings.Split(strings.TrimSuffix(invalid, "\r\n"), "\r\n") {
+		if len(line) > icalLineOctets || !utf8.ValidString(line) {
+			t.Errorf("Expected invalid UTF-8 to be replaced and folded, got %q", line)
+		}
+	}
+}
@@ -0,0 +1,40 @@
+package controllers
+
+import (
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestCalendarSearchFilter(t *testing.T) {
+	from := time.Date(2025, time.September, 1, 0, 0, 0, 0, campusLocation)
+	to := time.Date(2025, time.September, 7, 0, 0, 0, 0, campusLocation)
+	filter := calendarSearchFilter(map[string][]string{
+		"q":          {"career fair"},
+		"event_type": {"Workshop, Fair", ""},
+		"department": {"ECS"},
+	}, from, &to)
+
+	fields := make(map[string]any)
+	for _, element := range filter {
+		fields[element.Key] = element.Value
+	}
+	if got := fields["start_time"].(bson.M)["$lt"].(time.Time); !got.Equal(to.AddDate(0, 0, 1)) {
+		t.Errorf("Expected events starting before the day after to, got %v", got)
+	}
+	eventTypes := fields["event_type"].(bson.M)["$in"].(bson.A)
+	if len(eventTypes) != 2 || eventTypes[1].(primitive.Regex).Pattern != "^Fair$" {
+		t.Errorf("Expected two exact event types, got %v", eventTypes)
+	}
+	if _, ok := fields["department"]; !ok {
+		t.Error("Expected a department filter")
+	}
+	if _, ok := fields["topic"]; ok {
+		t.Error("Expected no topic filter when none is given")
+	}
+	if _, ok := fields["$or"]; !ok {
+		t.Error("Expected a free text filter on summary and description")
+	}
+}
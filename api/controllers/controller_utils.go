@@ -1,10 +1,13 @@
 package controllers
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"log"
 	"net/http"
+	"strings"
 
 	"github.com/UTDNebula/nebula-api/api/schema"
 	"github.com/getsentry/sentry-go"
@@ -27,6 +30,44 @@ func respond[T any](c *gin.Context, status int, message string, data T) {
 	)
 }
 
+// Responds with data along with an ETag of its version, or with http.StatusNotModified and no body
+// if the request's If-None-Match header already has that ETag. The data is only produced when it has to be sent.
+func respondWithETag[T any](c *gin.Context, version string, data func() T) {
+	hash := sha256.Sum256([]byte(version))
+	etag := `"` + hex.EncodeToString(hash[:16]) + `"`
+
+	// Clients have to revalidate, so they always get the latest content but only download it when it changed
+	c.Header("ETag", etag)
+	c.Header("Cache-Control", "no-cache")
+	if etagMatches(c.GetHeader("If-None-Match"), etag) {
+		c.AbortWithStatus(http.StatusNotModified)
+		return
+	}
+	respond(c, http.StatusOK, "success", data())
+}
+
+// Whether an If-None-Match header lists the ETag, comparing weakly as RFC 9110 specifies for If-None-Match
+func etagMatches(header string, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == "*" || candidate == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// Splits a comma-separated query parameter into its trimmed, non-empty values
+func commaSeparatedQuery(c *gin.Context, key string) []string {
+	var values []string
+	for _, value := range strings.Split(c.Query(key), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
 // Builds a MongoDB filter for type T based on the given flag search or byid
 func getQuery[T any](flag string, c *gin.Context) (bson.M, error) {
 	switch flag {
@@ -55,16 +96,26 @@ func getQuery[T any](flag string, c *gin.Context) (bson.M, error) {
 
 // Helper function for logging and responding to a generic internal server error.
 func respondWithInternalError(c *gin.Context, err error) {
-	// Note that we use log.Output here to be able to set the stack depth to the frame above this one (2),
-	// which allows us to log the location this function was called from
-	log.Output(2, fmt.Sprintf("INTERNAL SERVER ERROR: %s", err.Error()))
+	// Log the location this function was called from rather than this one
+	logErrorFrom(c, err, 3)
+	respond(c, http.StatusInternalServerError, "error", err.Error())
+}
+
+// logError logs an error along with the location it was logged from and captures it with Sentry, without responding
+func logError(c *gin.Context, err error) {
+	logErrorFrom(c, err, 3)
+}
+
+// logErrorFrom logs an error and captures it with Sentry.
+// Note that we use log.Output here to be able to set the stack depth, 1 being this function, which allows us to log the location of a caller.
+func logErrorFrom(c *gin.Context, err error, depth int) {
+	log.Output(depth, fmt.Sprintf("INTERNAL SERVER ERROR: %s", err.Error()))
 	// Capture error with Sentry
 	if hub := sentrygin.GetHubFromContext(c); hub != nil {
 		hub.WithScope(func(scope *sentry.Scope) {
 			hub.CaptureException(err)
 		})
 	}
-	respond(c, http.StatusInternalServerError, "error", err.Error())
 }
 
 // Attempts to convert the given parameter to an ObjectID for use with MongoDB.
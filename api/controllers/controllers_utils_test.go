@@ -106,3 +106,44 @@ func TestRespond(t *testing.T) {
 	}
 
 }
+
+// TestRespondWithETag validates that responses are only produced and sent again when their version changed.
+func TestRespondWithETag(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	produced := 0
+	request := func(ifNoneMatch string, version string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+		if ifNoneMatch != "" {
+			c.Request.Header.Set("If-None-Match", ifNoneMatch)
+		}
+		respondWithETag(c, version, func() []string {
+			produced++
+			return []string{version}
+		})
+		return w
+	}
+
+	first := request("", "v1")
+	etag := first.Header().Get("ETag")
+	if first.Code != http.StatusOK || etag == "" {
+		t.Fatalf("Expected 200 with an ETag, got %d with %q", first.Code, etag)
+	}
+
+	if unchanged := request(etag, "v1"); unchanged.Code != http.StatusNotModified || unchanged.Body.Len() != 0 {
+		t.Errorf("Expected 304 without a body for an unchanged version, got %d with %q", unchanged.Code, unchanged.Body.String())
+	}
+	if listed := request(`"stale", W/`+etag, "v1"); listed.Code != http.StatusNotModified {
+		t.Errorf("Expected 304 when the ETag is one of several, weak or not, got %d", listed.Code)
+	}
+	if produced != 1 {
+		t.Errorf("Expected the data to only be produced for the 200, produced %d times", produced)
+	}
+
+	changed := request(etag, "v2")
+	if changed.Code != http.StatusOK || changed.Header().Get("ETag") == etag {
+		t.Errorf("Expected 200 with a new ETag for a changed version, got %d with %q", changed.Code, changed.Header().Get("ETag"))
+	}
+}
@@ -0,0 +1,390 @@
+package controllers
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/gin-gonic/gin"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/UTDNebula/nebula-api/api/configs"
+	"github.com/UTDNebula/nebula-api/api/schema"
+)
+
+// How long the fallback course index is used before being rebuilt
+const courseIndexTTL = 6 * time.Hour
+
+// Words too common in course titles and descriptions to be worth matching
+var courseSearchStopWords = map[string]bool{
+	"a": true, "an": true, "and": true, "for": true, "in": true, "of": true, "on": true, "the": true, "to": true, "with": true,
+}
+
+// The searchable text of a course in the fallback index
+type indexedCourse struct {
+	id           primitive.ObjectID
+	prefix       string // lowercase
+	number       string // lowercase
+	catalogYear  string
+	title        string // folded
+	titleWords   []string
+	descriptions []string // words of the description
+}
+
+// An in-process index of every course used when Atlas Search isn't available
+var courseIndex = newRefreshingIndex(courseIndexTTL, buildCourseIndex)
+
+// Whether the Atlas Search index of the courses is usable, checked again as often as the fallback index is rebuilt
+var atlasCourseSearch = newRefreshingIndex(courseIndexTTL, checkAtlasCourseSearch)
+
+// @Id				courseFuzzySearch
+// @Router			/course/search [get]
+// @Tags			Courses
+// @Description	"Returns paginated list of courses ranked by how well their code, title and description match the search string, tolerating typos. Course codes can be given with or without a space (e.g. cs3345 or CS 3345). Uses Atlas Search when available, and an in-process index otherwise."
+// @Produce		json
+// @Param			q		query		string								true	"Search string"
+// @Param			offset	query		number								false	"The starting position of the current page of courses (e.g. For starting at the 17th course, offset=16)."
+// @Success		200		{object}	schema.APIResponse[[]schema.Course]	"A list of courses, most relevant first"
+// @Failure		500		{object}	schema.APIResponse[string]			"A string describing the error"
+// @Failure		400		{object}	schema.APIResponse[string]			"A string describing the error"
+func CourseFuzzySearch(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	q := strings.Join(strings.Fields(c.Query("q")), " ")
+	if q == "" {
+		respond(c, http.StatusBadRequest, "error", "q is required")
+		return
+	}
+	var offset int64
+	if value := c.Query("offset"); value != "" {
+		var err error
+		if offset, err = strconv.ParseInt(value, 10, 64); err != nil || offset < 0 {
+			respond(c, http.StatusBadRequest, "offset is not type integer", value)
+			return
+		}
+	}
+	limit := configs.GetEnvLimit()
+
+	// Every page comes from the same source so paging through the results doesn't switch between rankings
+	atlas, err := atlasCourseSearch.Get(ctx)
+	if err != nil {
+		respondWithInternalError(c, err)
+		return
+	}
+
+	courses := []schema.Course{}
+	if atlas {
+		pipeline := append(buildCourseSearchPipeline(q),
+			bson.D{{Key: "$skip", Value: offset}},
+			bson.D{{Key: "$limit", Value: limit}},
+		)
+		cursor, err := courseCollection.Aggregate(ctx, pipeline)
+		if err != nil {
+			respondWithInternalError(c, err)
+			return
+		}
+		if err = cursor.All(ctx, &courses); err != nil {
+			respondWithInternalError(c, err)
+			return
+		}
+	} else {
+		index, err := courseIndex.Get(ctx)
+		if err != nil {
+			respondWithInternalError(c, err)
+			return
+		}
+		ids := searchCourseIndex(index, q)
+		ids = ids[min(int(offset), len(ids)):]
+		ids = ids[:min(int(limit), len(ids))]
+		if courses, err = findCoursesInOrder(ctx, ids); err != nil {
+			respondWithInternalError(c, err)
+			return
+		}
+	}
+
+	respond(c, http.StatusOK, "success", courses)
+}
+
+// buildCourseSearchPipeline constructs the pipeline to perform fuzzy search on keyword q,
+// matching course codes exactly and titles and descriptions fuzzily
+func buildCourseSearchPipeline(q string) mongo.Pipeline {
+	var should bson.A
+	fuzzyConfigs := []schema.FuzzySearchConfig{
+		{Field: "title", MaxEdits: 2, BoostScore: 5},
+		{Field: "description", MaxEdits: 1, BoostScore: 1},
+	}
+	for _, config := range fuzzyConfigs {
+		should = append(should, bson.D{
+			{Key: "text", Value: bson.D{
+				{Key: "query", Value: q},
+				{Key: "path", Value: config.Field},
+				{Key: "fuzzy", Value: bson.D{
+					{Key: "maxEdits", Value: config.MaxEdits},
+					{Key: "prefixLength", Value: 2},
+				}},
+				{Key: "score", Value: bson.D{
+					{Key: "boost", Value: bson.D{{Key: "value", Value: config.BoostScore}}},
+				}},
+			}},
+		})
+	}
+
+	// Codes such as "cs3345" are split into their prefix and number, both of which have to match
+	if match := courseCodePattern.FindStringSubmatch(strings.ToLower(q)); match != nil {
+		must := bson.A{bson.D{{Key: "text", Value: bson.D{
+			{Key: "query", Value: match[1]},
+			{Key: "path", Value: "subject_prefix"},
+		}}}}
+		if match[2] != "" {
+			must = append(must, bson.D{{Key: "wildcard", Value: bson.D{
+				{Key: "query", Value: match[2] + "*"},
+				{Key: "path", Value: "course_number"},
+				{Key: "allowAnalyzedField", Value: true},
+			}}})
+		}
+		should = append(should, bson.D{{Key: "compound", Value: bson.D{
+			{Key: "must", Value: must},
+			{Key: "score", Value: bson.D{
+				{Key: "boost", Value: bson.D{{Key: "value", Value: 10}}},
+			}},
+		}}})
+	}
+
+	return mongo.Pipeline{
+		bson.D{
+			{Key: "$search", Value: bson.D{
+				// Name of the index search of this collection
+				{Key: "index", Value: "course_searches"},
+				{Key: "compound", Value: bson.D{
+					{Key: "should", Value: should},
+					{Key: "minimumShouldMatch", Value: 1},
+				}},
+			}},
+		},
+
+		// Sort the results based on relevancy, then by code for determinism
+		bson.D{
+			{Key: "$sort", Value: bson.D{
+				{Key: "score", Value: bson.D{{Key: "$meta", Value: "searchScore"}}},
+				{Key: "subject_prefix", Value: 1},
+				{Key: "course_number", Value: 1},
+				{Key: "catalog_year", Value: -1},
+			}},
+		},
+	}
+}
+
+// checkAtlasCourseSearch checks whether the Atlas Search index of the courses exists and has courses in it.
+// Atlas returns nothing rather than an error when the index is missing, so an empty index counts as unusable,
+// as does a database without Atlas Search. Any other error is returned so the next request checks again.
+func checkAtlasCourseSearch(ctx context.Context) (bool, error) {
+	var meta []struct {
+		Count struct {
+			LowerBound int64 `bson:"lowerBound"`
+		} `bson:"count"`
+	}
+	cursor, err := courseCollection.Aggregate(ctx, mongo.Pipeline{
+		bson.D{{Key: "$searchMeta", Value: bson.D{
+			{Key: "index", Value: "course_searches"},
+			{Key: "exists", Value: bson.D{{Key: "path", Value: "title"}}},
+			{Key: "count", Value: bson.D{{Key: "type", Value: "lowerBound"}, {Key: "threshold", Value: 1}}},
+		}}},
+	})
+	if err == nil {
+		err = cursor.All(ctx, &meta)
+	}
+	if err != nil && !searchUnsupported(err) {
+		return false, err
+	}
+	if err != nil || len(meta) == 0 || meta[0].Count.LowerBound == 0 {
+		if err == nil {
+			err = errors.New("the course_searches index is empty")
+		}
+		log.Printf("Atlas course search unavailable, using the fallback index: %s", err.Error())
+		return false, nil
+	}
+	return true, nil
+}
+
+// Server error codes of databases without Atlas Search: unrecognized pipeline stage, search not enabled,
+// and search stages only being allowed on Atlas
+var searchUnsupportedCodes = []int{40324, 31082, 6047401}
+
+// searchUnsupported reports whether an error means the database doesn't support Atlas Search at all
+func searchUnsupported(err error) bool {
+	var serverError mongo.ServerError
+	if !errors.As(err, &serverError) {
+		return false
+	}
+	return slices.ContainsFunc(searchUnsupportedCodes, serverError.HasErrorCode)
+}
+
+// buildCourseIndex extracts the searchable text of every course
+func buildCourseIndex(ctx context.Context) ([]indexedCourse, error) {
+	var courses []schema.Course
+	cursor, err := courseCollection.Find(ctx, bson.M{}, options.Find().SetProjection(bson.M{
+		"subject_prefix": 1, "course_number": 1, "title": 1, "description": 1, "catalog_year": 1,
+	}))
+	if err != nil {
+		return nil, err
+	}
+	if err = cursor.All(ctx, &courses); err != nil {
+		return nil, err
+	}
+
+	index := make([]indexedCourse, 0, len(courses))
+	for _, course := range courses {
+		index = append(index, newIndexedCourse(course))
+	}
+	return index, nil
+}
+
+// newIndexedCourse extracts the searchable text of a course
+func newIndexedCourse(course schema.Course) indexedCourse {
+	return indexedCourse{
+		id:           course.Id,
+		prefix:       strings.ToLower(strings.TrimSpace(course.Subject_prefix)),
+		number:       strings.ToLower(strings.TrimSpace(course.Course_number)),
+		catalogYear:  course.Catalog_year,
+		title:        strings.Join(courseSearchWords(course.Title), " "),
+		titleWords:   courseSearchWords(course.Title),
+		descriptions: courseSearchWords(course.Description),
+	}
+}
+
+// courseSearchWords splits text into lowercase words, leaving out stop words
+func courseSearchWords(text string) []string {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	return slices.DeleteFunc(words, func(word string) bool { return courseSearchStopWords[word] })
+}
+
+// searchCourseIndex ranks the indexed courses matching the query the way the Atlas search does, returning their IDs best first
+func searchCourseIndex(courses []indexedCourse, q string) []primitive.ObjectID {
+	code := courseCodePattern.FindStringSubmatch(strings.ToLower(q))
+	words := courseSearchWords(q)
+	phrase := strings.Join(words, " ")
+
+	type scoredCourse struct {
+		course indexedCourse
+		score  float64
+	}
+	var scored []scoredCourse
+	for _, course := range courses {
+		score := 0.0
+		if code != nil && course.prefix == code[1] && strings.HasPrefix(course.number, code[2]) {
+			score += 10
+			if course.number == code[2] {
+				score += 10
+			}
+		}
+		for _, word := range words {
+			score += 5 * wordMatchScore(word, course.titleWords, 2)
+			score += 1 * wordMatchScore(word, course.descriptions, 1)
+		}
+		if phrase != "" && strings.Contains(course.title, phrase) {
+			score += 5
+		}
+		if score > 0 {
+			scored = append(scored, scoredCourse{course: course, score: score})
+		}
+	}
+
+	slices.SortFunc(scored, func(a, b scoredCourse) int {
+		return cmp.Or(
+			cmp.Compare(b.score, a.score),
+			strings.Compare(a.course.prefix, b.course.prefix),
+			strings.Compare(a.course.number, b.course.number),
+			strings.Compare(b.course.catalogYear, a.course.catalogYear),
+		)
+	})
+	ids := make([]primitive.ObjectID, len(scored))
+	for i, course := range scored {
+		ids[i] = course.course.id
+	}
+	return ids
+}
+
+// wordMatchScore rates how well a query word matches the best of the words from 0 to 1:
+// exactly, as a prefix of at least 3 letters, or within the maximum edits with the same first 2 letters as Atlas fuzzy matching does
+func wordMatchScore(word string, words []string, maxEdits int) float64 {
+	best := 0.0
+	for _, candidate := range words {
+		switch {
+		case candidate == word:
+			return 1
+		case len(word) >= 3 && strings.HasPrefix(candidate, word):
+			best = max(best, 0.8)
+		case len(word) >= 4 && len(candidate) >= 2 && strings.HasPrefix(word, candidate[:2]):
+			// Short words tolerate fewer typos so they don't match everything
+			allowed := min(maxEdits, (len(word)-2)/3)
+			if allowed > 0 && editDistance(word, candidate) <= allowed {
+				best = max(best, 0.6)
+			}
+		}
+	}
+	return best
+}
+
+// editDistance is the number of insertions, deletions, substitutions and swaps of adjacent runes needed to turn one string into the other,
+// counting swaps as one edit like Atlas fuzzy matching does
+func editDistance(a string, b string) int {
+	source, target := []rune(a), []rune(b)
+	// Distances between prefixes of source and target, keeping the last three rows
+	beforePrevious := make([]int, len(target)+1)
+	previous := make([]int, len(target)+1)
+	current := make([]int, len(target)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(source); i++ {
+		current[0] = i
+		for j := 1; j <= len(target); j++ {
+			substitution := previous[j-1]
+			if source[i-1] != target[j-1] {
+				substitution++
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, substitution)
+			if i > 1 && j > 1 && source[i-1] == target[j-2] && source[i-2] == target[j-1] {
+				current[j] = min(current[j], beforePrevious[j-2]+1)
+			}
+		}
+		beforePrevious, previous, current = previous, current, beforePrevious
+	}
+	return previous[len(target)]
+}
+
+// findCoursesInOrder retrieves the courses with the given IDs, in the same order
+func findCoursesInOrder(ctx context.Context, ids []primitive.ObjectID) ([]schema.Course, error) {
+	courses := []schema.Course{}
+	if len(ids) == 0 {
+		return courses, nil
+	}
+	cursor, err := courseCollection.Find(ctx, bson.M{"_id": bson.M{"$in": ids}})
+	if err != nil {
+		return nil, err
+	}
+	if err = cursor.All(ctx, &courses); err != nil {
+		return nil, err
+	}
+
+	position := make(map[primitive.ObjectID]int, len(ids))
+	for i, id := range ids {
+		position[id] = i
+	}
+	slices.SortFunc(courses, func(a, b schema.Course) int { return cmp.Compare(position[a.Id], position[b.Id]) })
+	return courses, nil
+}
@@ -0,0 +1,364 @@
+package controllers
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"math"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/UTDNebula/nebula-api/api/schema"
+)
+
+// How long the similar course index is used before being rebuilt
+const similarCourseIndexTTL = 24 * time.Hour
+
+// Number of similar courses returned by default, and at most
+const (
+	defaultSimilarCourses = 10
+	maxSimilarCourses     = 50
+)
+
+// Weights of each kind of similarity in the score, summing to 1
+const (
+	textSimilarityWeight         = 0.6
+	prerequisiteSimilarityWeight = 0.2
+	classLevelSimilarityWeight   = 0.1
+	coreFlagSimilarityWeight     = 0.1
+)
+
+// Titles say more about what a course covers than the same words in its description
+const titleTermWeight = 2
+
+// A course in the similar course index
+type similarCourseEntry struct {
+	course        schema.BasicCourse
+	code          string             // subject prefix and course number, uppercase
+	terms         map[string]float64 // TF-IDF vector of the title and description, normalized to unit length
+	prerequisites []string           // codes of the required courses, sorted
+	coreFlags     []string           // core flags of the sections of the course, sorted
+}
+
+// An in-process index of every course for finding similar ones, built at startup
+type similarCourseIndex struct {
+	courses             []similarCourseEntry
+	byId                map[primitive.ObjectID]int
+	codes               map[string]string // code of each course by the hex of its ID, as prerequisites reference them
+	documentFrequencies map[string]int    // number of courses each term appears in
+}
+
+// Fields of a course needed to index it
+var similarCourseProjection = bson.M{
+	"subject_prefix": 1, "course_number": 1, "title": 1, "description": 1, "credit_hours": 1,
+	"class_level": 1, "activity_type": 1, "catalog_year": 1, "prerequisites": 1,
+}
+
+var similarCourses = newRefreshingIndex(similarCourseIndexTTL, buildSimilarCourseIndex)
+
+// @Id				courseSimilar
+// @Router			/course/{id}/similar [get]
+// @Tags			Courses
+// @Description	"Returns the courses most similar to the course with given ID, as alternatives when its sections are full. Courses are ranked by the TF-IDF similarity of their titles and descriptions, shared prerequisites, being the same class level and sharing core flags. Other catalog years of the same course are left out, and each similar course is listed once."
+// @Produce		json
+// @Param			id		path		string										true	"ID of the course to find similar courses to"
+// @Param			limit	query		number										false	"Maximum number of courses, defaults to 10, at most 50"
+// @Success		200		{object}	schema.APIResponse[[]schema.SimilarCourse]	"Similar courses, most similar first"
+// @Failure		500		{object}	schema.APIResponse[string]					"A string describing the error"
+// @Failure		404		{object}	schema.APIResponse[string]					"A string describing the error"
+// @Failure		400		{object}	schema.APIResponse[string]					"A string describing the error"
+func CourseSimilar(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 30*time.Second)
+	defer cancel()
+
+	id, err := objectIDFromParam(c, "id")
+	if err != nil {
+		return
+	}
+	limit := defaultSimilarCourses
+	if value := c.Query("limit"); value != "" {
+		if limit, err = strconv.Atoi(value); err != nil || limit < 1 {
+			respond(c, http.StatusBadRequest, "error", "limit must be a positive integer")
+			return
+		}
+		limit = min(limit, maxSimilarCourses)
+	}
+
+	index, err := similarCourses.Get(ctx)
+	if err != nil {
+		respondWithInternalError(c, err)
+		return
+	}
+	var target similarCourseEntry
+	if position, ok := index.byId[*id]; ok {
+		target = index.courses[position]
+	} else if target, err = findSimilarCourseEntry(ctx, c, index, *id); err != nil {
+		return
+	}
+
+	respond(c, http.StatusOK, "success", index.similar(target, limit))
+}
+
+// findSimilarCourseEntry indexes a course added since the index was built, so it can be compared with the indexed courses.
+// Automatically responds with an error if the course can't be found.
+func findSimilarCourseEntry(ctx context.Context, c *gin.Context, index similarCourseIndex, id primitive.ObjectID) (similarCourseEntry, error) {
+	var course schema.Course
+	err := courseCollection.FindOne(ctx, bson.M{"_id": id}, options.FindOne().SetProjection(similarCourseProjection)).Decode(&course)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			respond(c, http.StatusNotFound, "error", "No courses with given ID")
+		} else {
+			respondWithInternalError(c, err)
+		}
+		return similarCourseEntry{}, err
+	}
+
+	values, err := sectionCollection.Distinct(ctx, "core_flags", bson.M{"course_reference": id})
+	if err != nil {
+		respondWithInternalError(c, err)
+		return similarCourseEntry{}, err
+	}
+	var flags []string
+	for _, value := range values {
+		if flag, ok := value.(string); ok {
+			flags = append(flags, flag)
+		}
+	}
+	return index.entry(course, courseTermFrequencies(course), flags), nil
+}
+
+// buildSimilarCourseIndex indexes every course along with the core flags of its sections
+func buildSimilarCourseIndex(ctx context.Context) (similarCourseIndex, error) {
+	var courses []schema.Course
+	cursor, err := courseCollection.Find(ctx, bson.M{}, options.Find().SetProjection(similarCourseProjection))
+	if err != nil {
+		return similarCourseIndex{}, err
+	}
+	if err = cursor.All(ctx, &courses); err != nil {
+		return similarCourseIndex{}, err
+	}
+
+	var coreFlags []struct {
+		Course primitive.ObjectID `bson:"_id"`
+		Flags  []string           `bson:"flags"`
+	}
+	cursor, err = sectionCollection.Aggregate(ctx, mongo.Pipeline{
+		bson.D{{Key: "$match", Value: bson.M{"core_flags.0": bson.M{"$exists": true}}}},
+		bson.D{{Key: "$unwind", Value: "$core_flags"}},
+		bson.D{{Key: "$group", Value: bson.M{"_id": "$course_reference", "flags": bson.M{"$addToSet": "$core_flags"}}}},
+	})
+	if err != nil {
+		return similarCourseIndex{}, err
+	}
+	if err = cursor.All(ctx, &coreFlags); err != nil {
+		return similarCourseIndex{}, err
+	}
+	flagsByCourse := make(map[primitive.ObjectID][]string, len(coreFlags))
+	for _, course := range coreFlags {
+		flagsByCourse[course.Course] = course.Flags
+	}
+
+	return newSimilarCourseIndex(courses, flagsByCourse), nil
+}
+
+// newSimilarCourseIndex computes the TF-IDF vectors of the courses and collects their prerequisites and core flags
+func newSimilarCourseIndex(courses []schema.Course, coreFlags map[primitive.ObjectID][]string) similarCourseIndex {
+	index := similarCourseIndex{
+		courses:             make([]similarCourseEntry, len(courses)),
+		byId:                make(map[primitive.ObjectID]int, len(courses)),
+		codes:               make(map[string]string, len(courses)),
+		documentFrequencies: make(map[string]int),
+	}
+
+	frequencies := make([]map[string]float64, len(courses))
+	for i, course := range courses {
+		frequencies[i] = courseTermFrequencies(course)
+		for term := range frequencies[i] {
+			index.documentFrequencies[term]++
+		}
+		index.codes[course.Id.Hex()] = courseCode(course.Subject_prefix, course.Course_number)
+	}
+
+	for i, course := range courses {
+		index.courses[i] = index.entry(course, frequencies[i], coreFlags[course.Id])
+		index.byId[course.Id] = i
+	}
+	return index
+}
+
+// courseTermFrequencies counts the words of the title and description of a course, weighing the title more
+func courseTermFrequencies(course schema.Course) map[string]float64 {
+	frequencies := make(map[string]float64)
+	for _, word := range courseSearchWords(course.Title) {
+		frequencies[word] += titleTermWeight
+	}
+	for _, word := range courseSearchWords(course.Description) {
+		frequencies[word]++
+	}
+	return frequencies
+}
+
+// entry computes the TF-IDF vector of a course from the term frequencies in the index and resolves its prerequisites to course codes
+func (index similarCourseIndex) entry(course schema.Course, frequencies map[string]float64, coreFlags []string) similarCourseEntry {
+	terms := make(map[string]float64, len(frequencies))
+	length := 0.0
+	for term, frequency := range frequencies {
+		// Smoothed so terms in every course still count a little
+		idf := math.Log(float64(1+len(index.courses))/float64(1+index.documentFrequencies[term])) + 1
+		terms[term] = frequency * idf
+		length += terms[term] * terms[term]
+	}
+	length = math.Sqrt(length)
+	for term := range terms {
+		terms[term] /= length
+	}
+
+	var prerequisites []string
+	if course.Prerequisites != nil {
+		prerequisites = requiredCourses(*course.Prerequisites, index.codes)
+	}
+	flags := slices.Clone(coreFlags)
+	slices.Sort(flags)
+
+	return similarCourseEntry{
+		course: schema.BasicCourse{
+			Id:             course.Id,
+			Subject_prefix: course.Subject_prefix,
+			Course_number:  course.Course_number,
+			Title:          course.Title,
+			Credit_hours:   course.Credit_hours,
+			Class_level:    course.Class_level,
+			Activity_type:  course.Activity_type,
+			Catalog_year:   course.Catalog_year,
+		},
+		code:          courseCode(course.Subject_prefix, course.Course_number),
+		terms:         terms,
+		prerequisites: prerequisites,
+		coreFlags:     slices.Compact(flags),
+	}
+}
+
+// requiredCourses collects the codes of every course in a requirement and the requirements nested in it, sorted.
+// Class references are the IDs of a catalog year of a course, so they're resolved to codes for every year to count as the same course.
+// References to courses that aren't known are kept as is.
+func requiredCourses(requirement schema.CollectionRequirement, codes map[string]string) []string {
+	var references []string
+	var collect func(options []interface{})
+	collect = func(options []interface{}) {
+		for _, option := range options {
+			switch option := option.(type) {
+			case schema.CourseRequirement:
+				if code, ok := codes[option.ClassReference]; ok {
+					references = append(references, code)
+				} else {
+					references = append(references, option.ClassReference)
+				}
+			case schema.CollectionRequirement:
+				collect(option.Options)
+			case schema.ChoiceRequirement:
+				if option.Choices != nil {
+					collect(option.Choices.Options)
+				}
+			}
+		}
+	}
+	collect(requirement.Options)
+	slices.Sort(references)
+	return slices.Compact(references)
+}
+
+// similar ranks the other courses by similarity to the target course, keeping the most similar catalog year of each
+func (index similarCourseIndex) similar(target similarCourseEntry, limit int) []schema.SimilarCourse {
+	best := make(map[string]schema.SimilarCourse)
+	for _, other := range index.courses {
+		if other.code == target.code {
+			continue
+		}
+		similar := compareCourses(target, other)
+		if similar.Score == 0 {
+			continue
+		}
+		if previous, ok := best[other.code]; ok && (previous.Score > similar.Score ||
+			(previous.Score == similar.Score && previous.Course.Catalog_year >= similar.Course.Catalog_year)) {
+			continue
+		}
+		best[other.code] = similar
+	}
+
+	ranked := make([]schema.SimilarCourse, 0, len(best))
+	for _, similar := range best {
+		ranked = append(ranked, similar)
+	}
+	slices.SortFunc(ranked, func(a, b schema.SimilarCourse) int {
+		return cmp.Or(
+			cmp.Compare(b.Score, a.Score),
+			strings.Compare(a.Course.Subject_prefix, b.Course.Subject_prefix),
+			strings.Compare(a.Course.Course_number, b.Course.Course_number),
+		)
+	})
+	return ranked[:min(limit, len(ranked))]
+}
+
+// compareCourses scores how similar another course is to the target, from 0 to 1.
+// Courses with nothing in common but their class level score 0, so they aren't recommended just for being the same level.
+func compareCourses(target similarCourseEntry, other similarCourseEntry) schema.SimilarCourse {
+	similar := schema.SimilarCourse{
+		Course:               other.course,
+		Shared_prerequisites: sharedValues(target.prerequisites, other.prerequisites),
+		Same_class_level:     target.course.Class_level != "" && strings.EqualFold(target.course.Class_level, other.course.Class_level),
+		Shared_core_flags:    sharedValues(target.coreFlags, other.coreFlags),
+	}
+
+	// Dot product of the unit vectors, iterating over the smaller one
+	small, large := target.terms, other.terms
+	if len(large) < len(small) {
+		small, large = large, small
+	}
+	for term, weight := range small {
+		similar.Text_similarity += weight * large[term]
+	}
+	similar.Text_similarity = math.Round(similar.Text_similarity*10000) / 10000
+
+	if similar.Text_similarity == 0 && len(similar.Shared_prerequisites) == 0 && len(similar.Shared_core_flags) == 0 {
+		return similar
+	}
+	score := textSimilarityWeight*similar.Text_similarity +
+		prerequisiteSimilarityWeight*jaccardSimilarity(target.prerequisites, other.prerequisites) +
+		coreFlagSimilarityWeight*jaccardSimilarity(target.coreFlags, other.coreFlags)
+	if similar.Same_class_level {
+		score += classLevelSimilarityWeight
+	}
+	similar.Score = math.Round(score*10000) / 10000
+	return similar
+}
+
+// sharedValues returns the values in both sorted lists, never nil
+func sharedValues(a []string, b []string) []string {
+	shared := []string{}
+	for _, value := range a {
+		if _, found := slices.BinarySearch(b, value); found {
+			shared = append(shared, value)
+		}
+	}
+	return shared
+}
+
+// jaccardSimilarity is the share of the values in either sorted list that are in both
+func jaccardSimilarity(a []string, b []string) float64 {
+	shared := len(sharedValues(a, b))
+	union := len(a) + len(b) - shared
+	if union == 0 {
+		return 0
+	}
+	return float64(shared) / float64(union)
+}
@@ -1,11 +1,18 @@
 package controllers
 
 import (
+	"errors"
 	"net/http"
 	"net/http/httptest"
+	"slices"
 	"testing"
 
 	"github.com/gin-gonic/gin"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"github.com/UTDNebula/nebula-api/api/schema"
 )
 
 func TestCourseById_InvalidID(t *testing.T) {
@@ -25,3 +32,141 @@ func TestCourseById_InvalidID(t *testing.T) {
 		t.Errorf("Expected status 400 for invalid ID, got %d", w.Code)
 	}
 }
+
+func TestSearchCourseIndex(t *testing.T) {
+	course := func(prefix string, number string, title string, description string) schema.Course {
+		return schema.Course{Id: primitive.NewObjectID(), Subject_prefix: prefix, Course_number: number, Title: title, Description: description, Catalog_year: "24"}
+	}
+	dataStructures := course("CS", "3345", "Data Structures and Introduction to Algorithmic Analysis", "Analysis of algorithms including time complexity.")
+	programming := course("CS", "1337", "Computer Science I", "Introduction to programming and data structures.")
+	// Words of the query also match as prefixes of longer words, so "data" matches databases
+	databases := course("CS", "4347", "Database Systems", "Design of relational databases.")
+	accounting := course("ACCT", "2301", "Introductory Financial Accounting", "Accounting principles.")
+
+	var index []indexedCourse
+	for _, c := range []schema.Course{dataStructures, programming, databases, accounting} {
+		index = append(index, newIndexedCourse(c))
+	}
+
+	testCases := map[string]struct {
+		Query    string
+		Expected []primitive.ObjectID
+	}{
+		"CompactCode":  {Query: "cs3345", Expected: []primitive.ObjectID{dataStructures.Id}},
+		"SpacedCode":   {Query: "CS 3345", Expected: []primitive.ObjectID{dataStructures.Id}},
+		"Prefix":       {Query: "cs", Expected: []primitive.ObjectID{programming.Id, dataStructures.Id, databases.Id}},
+		"Title":        {Query: "data structures", Expected: []primitive.ObjectID{dataStructures.Id, databases.Id, programming.Id}},
+		"Typo":         {Query: "data strcutures", Expected: []primitive.ObjectID{dataStructures.Id, databases.Id, programming.Id}},
+		"TitlePrefix":  {Query: "accou", Expected: []primitive.ObjectID{accounting.Id}},
+		"NoMatch":      {Query: "zoology", Expected: nil},
+		"OnlyStopWord": {Query: "the", Expected: nil},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			result := searchCourseIndex(index, tc.Query)
+			if len(result) != len(tc.Expected) {
+				t.Fatalf("Expected %v, got %v", tc.Expected, result)
+			}
+			for i := range result {
+				if result[i] != tc.Expected[i] {
+					t.Errorf("Expected %v at position %d, got %v", tc.Expected[i], i, result[i])
+				}
+			}
+		})
+	}
+}
+
+func TestEditDistance(t *testing.T) {
+	testCases := map[string]struct {
+		A, B     string
+		Expected int
+	}{
+		"Same":          {A: "data", B: "data", Expected: 0},
+		"Empty":         {A: "", B: "abc", Expected: 3},
+		"Transposition": {A: "strcutures", B: "structures", Expected: 1},
+		"Substitution":  {A: "kitten", B: "sitten", Expected: 1},
+		"Classic":       {A: "kitten", B: "sitting", Expected: 3},
+		"Runes":         {A: "josé", B: "jose", Expected: 1},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			if result := editDistance(tc.A, tc.B); result != tc.Expected {
+				t.Errorf("Expected %d, got %d", tc.Expected, result)
+			}
+		})
+	}
+}
+
+func TestSearchUnsupported(t *testing.T) {
+	if !searchUnsupported(mongo.CommandError{Code: 40324, Message: "Unrecognized pipeline stage name: '$searchMeta'"}) {
+		t.Error("Expected a database without the search stages not to support Atlas Search")
+	}
+	if searchUnsupported(mongo.CommandError{Code: 6, Message: "host unreachable"}) || searchUnsupported(errors.New("connection reset")) {
+		t.Error("Expected transient errors to say nothing about Atlas Search")
+	}
+}
+
+func TestSimilarCourses(t *testing.T) {
+	prerequisites := func(references ...string) *schema.CollectionRequirement {
+		var options []interface{}
+		for _, reference := range references {
+			options = append(options, *schema.NewCourseRequirement(reference, "C"))
+		}
+		// Nested the way "one of" prerequisites are stored
+		return schema.NewCollectionRequirement("REQUIRED", 1, []interface{}{*schema.NewCollectionRequirement("CHOOSE", 1, options)})
+	}
+	course := func(prefix string, number string, year string, level string, title string, description string) schema.Course {
+		return schema.Course{Id: primitive.NewObjectID(), Subject_prefix: prefix, Course_number: number, Catalog_year: year, Class_level: level, Title: title, Description: description}
+	}
+	// Prerequisites reference the ID of a catalog year of a course
+	discrete := course("CS", "2305", "24", "Undergraduate", "Discrete Mathematics for Computing I", "Logic, sets and proofs.")
+	discreteOld := course("CS", "2305", "23", "Undergraduate", "Discrete Mathematics for Computing I", "Logic, sets and proofs.")
+	dataStructures := course("CS", "3345", "24", "Undergraduate", "Data Structures and Algorithmic Analysis", "Analysis of algorithms, trees, graphs and hashing.")
+	dataStructures.Prerequisites = prerequisites(discrete.Id.Hex(), "unknown")
+	algorithms := course("CS", "4349", "24", "Undergraduate", "Advanced Algorithm Design and Analysis", "Design and analysis of algorithms for graphs.")
+	algorithms.Prerequisites = prerequisites(dataStructures.Id.Hex(), discreteOld.Id.Hex())
+	algorithmsOld := course("CS", "4349", "23", "Undergraduate", "Advanced Algorithm Design and Analysis", "Design and analysis of algorithms for graphs.")
+	graduate := course("CS", "6363", "24", "Graduate", "Design and Analysis of Computer Algorithms", "Design and analysis of algorithms.")
+	history := course("HIST", "1301", "24", "Undergraduate", "U.S. History Survey", "American history before 1865.")
+	government := course("GOVT", "2305", "24", "Undergraduate", "American National Government", "The constitution and institutions of government.")
+	dataStructuresOld := course("CS", "3345", "23", "Undergraduate", "Data Structures and Algorithmic Analysis", "Analysis of algorithms, trees, graphs and hashing.")
+
+	index := newSimilarCourseIndex(
+		[]schema.Course{discrete, discreteOld, dataStructures, algorithms, algorithmsOld, graduate, history, government, dataStructuresOld},
+		map[primitive.ObjectID][]string{history.Id: {"060"}, government.Id: {"070", "060"}},
+	)
+
+	if prerequisites := index.courses[index.byId[algorithms.Id]].prerequisites; !slices.Equal(prerequisites, []string{"CS 2305", "CS 3345"}) {
+		t.Errorf("Expected the prerequisites to be resolved to course codes, got %v", prerequisites)
+	}
+
+	similar := index.similar(index.courses[index.byId[dataStructures.Id]], 10)
+	if len(similar) != 2 {
+		t.Fatalf("Expected CS 4349 and CS 6363 without other years of CS 3345 or unrelated courses, got %v", similar)
+	}
+	if similar[0].Course.Course_number != "4349" || similar[0].Course.Catalog_year != "24" {
+		t.Errorf("Expected the latest CS 4349 to be most similar, got %v", similar[0].Course)
+	}
+	if !slices.Equal(similar[0].Shared_prerequisites, []string{"CS 2305"}) || !similar[0].Same_class_level {
+		t.Errorf("Expected CS 4349 to share CS 2305 through another catalog year and the class level, got %v", similar[0])
+	}
+	if similar[1].Course.Course_number != "6363" || similar[1].Same_class_level || similar[1].Score >= similar[0].Score {
+		t.Errorf("Expected the graduate course to rank lower, got %v", similar[1])
+	}
+
+	// Only the shared core flag relates these
+	similar = index.similar(index.courses[index.byId[history.Id]], 10)
+	if len(similar) != 1 || similar[0].Course.Subject_prefix != "GOVT" || !slices.Equal(similar[0].Shared_core_flags, []string{"060"}) {
+		t.Errorf("Expected GOVT 2305 sharing core flag 060, got %v", similar)
+	}
+
+	// Courses added since the index was built are compared with the indexed ones
+	added := course("CS", "4348", "25", "Undergraduate", "Operating Systems Concepts", "Processes, threads and algorithms for scheduling.")
+	added.Prerequisites = prerequisites(dataStructuresOld.Id.Hex())
+	similar = index.similar(index.entry(added, courseTermFrequencies(added), nil), 10)
+	if len(similar) == 0 || similar[0].Course.Course_number != "4349" || !slices.Equal(similar[0].Shared_prerequisites, []string{"CS 3345"}) {
+		t.Errorf("Expected CS 4349 sharing CS 3345 to be most similar to the new course, got %v", similar)
+	}
+}
@@ -0,0 +1,305 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/UTDNebula/nebula-api/api/configs"
+
+	"github.com/UTDNebula/nebula-api/api/schema"
+
+	"github.com/gin-gonic/gin"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var courseAliasCollection *mongo.Collection = configs.GetCollection("course_aliases")
+
+// Relations explaining why a course is equivalent to the requested one
+const (
+	equivalenceSelf           = "self"
+	equivalenceInternalNumber = "internal_course_number"
+	equivalenceCrossListed    = "cross_listed"
+	equivalenceAlias          = "alias"
+)
+
+// Maximum number of expansion rounds, guards against alias chains growing without bound
+const maxEquivalenceRounds = 4
+
+// Fields needed to build an EquivalentCourse, avoids decoding whole courses with their requirements
+var equivalentCourseProjection = options.Find().SetProjection(bson.M{
+	"subject_prefix":         1,
+	"course_number":          1,
+	"title":                  1,
+	"credit_hours":           1,
+	"class_level":            1,
+	"activity_type":          1,
+	"catalog_year":           1,
+	"internal_course_number": 1,
+})
+
+// @Id				courseEquivalents
+// @Router			/course/{id}/equivalents [get]
+// @Tags			Courses
+// @Description	"Returns every course equivalent to the course with given ID, grouped by internal course number, cross-listing under another prefix in the same catalog year and explicit aliases from the course_aliases collection"
+// @Produce		json
+// @Param			id	path		string											true	"ID of the course to get equivalents for"
+// @Success		200	{object}	schema.APIResponse[[]schema.EquivalentCourse]	"A list of equivalent courses, including the course itself"
+// @Failure		500	{object}	schema.APIResponse[string]						"A string describing the error"
+// @Failure		404	{object}	schema.APIResponse[string]						"A string describing the error"
+// @Failure		400	{object}	schema.APIResponse[string]						"A string describing the error"
+func CourseEquivalents(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	var course schema.EquivalentCourse
+
+	objId, err := objectIDFromParam(c, "id")
+	if err != nil {
+		return
+	}
+
+	err = courseCollection.FindOne(ctx, bson.M{"_id": objId}).Decode(&course)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			respond(c, http.StatusNotFound, "error", "No courses with given ID")
+		} else {
+			respondWithInternalError(c, err)
+		}
+		return
+	}
+
+	equivalents, err := findEquivalentCourses(ctx, []schema.EquivalentCourse{course})
+	if err != nil {
+		respondWithInternalError(c, err)
+		return
+	}
+
+	respond(c, http.StatusOK, "success", equivalents)
+}
+
+// equivalentCoursesFilter widens a course filter to match every course equivalent to the ones it matches.
+// If the filter matches no courses it is returned unchanged.
+func equivalentCoursesFilter(ctx context.Context, courseFilter bson.M) (bson.M, error) {
+	var seeds []schema.EquivalentCourse
+
+	cursor, err := courseCollection.Find(ctx, courseFilter, equivalentCourseProjection)
+	if err != nil {
+		return nil, err
+	}
+	if err = cursor.All(ctx, &seeds); err != nil {
+		return nil, err
+	}
+	if len(seeds) == 0 {
+		return courseFilter, nil
+	}
+
+	equivalents, err := findEquivalentCourses(ctx, seeds)
+	if err != nil {
+		return nil, err
+	}
+
+	courseIDs := make([]primitive.ObjectID, 0, len(equivalents))
+	for _, course := range equivalents {
+		courseIDs = append(courseIDs, course.Id)
+	}
+	return bson.M{"_id": bson.M{"$in": courseIDs}}, nil
+}
+
+// findEquivalentCourses expands the seed courses to the closure of all courses sharing an internal course number,
+// cross-listed under another prefix, or linked through an explicit alias.
+func findEquivalentCourses(ctx context.Context, seeds []schema.EquivalentCourse) ([]schema.EquivalentCourse, error) {
+	found := make(map[primitive.ObjectID]bool)
+	seenInternal := make(map[string]bool)
+	seenCodes := make(map[string]bool)
+	seenCrossLists := make(map[string]bool)
+
+	var equivalents []schema.EquivalentCourse
+	frontier := make([]schema.EquivalentCourse, 0, len(seeds))
+	for _, seed := range seeds {
+		if !found[seed.Id] {
+			found[seed.Id] = true
+			seed.Relation = equivalenceSelf
+			frontier = append(frontier, seed)
+		}
+	}
+
+	for round := 0; round < maxEquivalenceRounds && len(frontier) > 0; round++ {
+		equivalents = append(equivalents, frontier...)
+
+		var internalNumbers []string
+		var codes []bson.M
+		var crossListNumbers []string
+		crossListKeys := make(map[string]bool)
+		for _, course := range frontier {
+			if course.Internal_course_number != "" && !seenInternal[course.Internal_course_number] {
+				seenInternal[course.Internal_course_number] = true
+				internalNumbers = append(internalNumbers, course.Internal_course_number)
+			}
+			code := courseCode(course.Subject_prefix, course.Course_number)
+			if !seenCodes[code] {
+				seenCodes[code] = true
+				codes = append(codes, bson.M{"subject_prefix": course.Subject_prefix, "course_number": course.Course_number})
+			}
+			key := crossListKey(course.BasicCourse)
+			if key != "" && !seenCrossLists[key] {
+				seenCrossLists[key] = true
+				crossListKeys[key] = true
+				crossListNumbers = append(crossListNumbers, course.Course_number)
+			}
+		}
+
+		var next []schema.EquivalentCourse
+		collect := func(courses []schema.EquivalentCourse, relation string) {
+			for _, course := range courses {
+				if !found[course.Id] {
+					found[course.Id] = true
+					course.Relation = relation
+					next = append(next, course)
+				}
+			}
+		}
+
+		// Courses sharing an internal course number, this also covers renumbering across catalog years
+		if len(internalNumbers) > 0 {
+			courses, err := findEquivalentCandidates(ctx, bson.M{"internal_course_number": bson.M{"$in": internalNumbers}})
+			if err != nil {
+				return nil, err
+			}
+			collect(courses, equivalenceInternalNumber)
+		}
+
+		// Courses offered under a different prefix with the same number and title in the same catalog year.
+		// Titles differ in case and spacing between prefixes, so they are compared by key rather than in the query.
+		if len(crossListNumbers) > 0 {
+			courses, err := findEquivalentCandidates(ctx, bson.M{"course_number": bson.M{"$in": crossListNumbers}})
+			if err != nil {
+				return nil, err
+			}
+			collect(crossListedCourses(courses, crossListKeys), equivalenceCrossListed)
+		}
+
+		// Courses explicitly linked through the alias table, in either direction
+		if len(codes) > 0 {
+			aliasCodes, err := findAliasedCodes(ctx, codes)
+			if err != nil {
+				return nil, err
+			}
+			if len(aliasCodes) > 0 {
+				courses, err := findEquivalentCandidates(ctx, bson.M{"$or": aliasCodes})
+				if err != nil {
+					return nil, err
+				}
+				collect(courses, equivalenceAlias)
+			}
+		}
+
+		frontier = next
+	}
+	// Keep whatever the last round found even if we stopped expanding
+	equivalents = append(equivalents, frontier...)
+
+	return equivalents, nil
+}
+
+// findEquivalentCandidates retrieves the courses matching the filter in the EquivalentCourse form
+func findEquivalentCandidates(ctx context.Context, filter bson.M) ([]schema.EquivalentCourse, error) {
+	var courses []schema.EquivalentCourse
+
+	cursor, err := courseCollection.Find(ctx, filter, equivalentCourseProjection)
+	if err != nil {
+		return nil, err
+	}
+	if err = cursor.All(ctx, &courses); err != nil {
+		return nil, err
+	}
+	return courses, nil
+}
+
+// findAliasedCodes returns the prefix and number filters of every course code aliased to one of the given codes
+func findAliasedCodes(ctx context.Context, codes []bson.M) ([]bson.M, error) {
+	var aliases []schema.CourseAlias
+
+	// Match the codes on either side of the alias
+	aliasMatches := make(bson.A, 0, 2*len(codes))
+	for _, code := range codes {
+		aliasMatches = append(aliasMatches,
+			code,
+			bson.M{"alias_prefix": code["subject_prefix"], "alias_number": code["course_number"]},
+		)
+	}
+
+	cursor, err := courseAliasCollection.Find(ctx, bson.M{"$or": aliasMatches})
+	if err != nil {
+		return nil, err
+	}
+	if err = cursor.All(ctx, &aliases); err != nil {
+		return nil, err
+	}
+
+	aliasCodes := make([]bson.M, 0, 2*len(aliases))
+	for _, alias := range aliases {
+		aliasCodes = append(aliasCodes,
+			bson.M{"subject_prefix": alias.Subject_prefix, "course_number": alias.Course_number},
+			bson.M{"subject_prefix": alias.Alias_prefix, "course_number": alias.Alias_number},
+		)
+	}
+	return aliasCodes, nil
+}
+
+// courseCode formats a prefix and number as a normalized course code, e.g. "CS 3345"
+func courseCode(prefix string, number string) string {
+	return strings.ToUpper(strings.TrimSpace(prefix)) + " " + strings.ToUpper(strings.TrimSpace(number))
+}
+
+// crossListKey identifies the courses which are cross-listed with each other: same number and title under any prefix
+// in the same catalog year, ignoring case and spacing. It's empty for courses which can't be told apart from unrelated ones
+// by their number and title, such as variable credit courses (e.g. 4V98) and generic titles like internships,
+// which need an explicit alias to be equivalent.
+func crossListKey(course schema.BasicCourse) string {
+	number := strings.ToUpper(strings.TrimSpace(course.Course_number))
+	title := strings.ToLower(strings.Join(strings.Fields(course.Title), " "))
+	if number == "" || title == "" || strings.Contains(number, "V") || genericCourseTitle(title) {
+		return ""
+	}
+	return strings.TrimSpace(course.Catalog_year) + "|" + number + "|" + title
+}
+
+// Titles shared by unrelated courses in every subject, and the starts of such titles
+var (
+	genericCourseTitles = map[string]bool{
+		"internship": true, "undergraduate internship": true, "research": true, "undergraduate research": true, "thesis": true,
+		"senior honors thesis": true, "dissertation": true, "practicum": true, "seminar": true, "cooperative education": true,
+	}
+	genericCourseTitlePrefixes = []string{"independent study", "individual instruction", "directed", "special topics", "topics in"}
+)
+
+// genericCourseTitle reports whether a lowercase title is too generic to identify a course
+func genericCourseTitle(title string) bool {
+	if genericCourseTitles[title] {
+		return true
+	}
+	for _, prefix := range genericCourseTitlePrefixes {
+		if strings.HasPrefix(title, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// crossListedCourses keeps the candidates whose cross-list key is one of the given keys
+func crossListedCourses(candidates []schema.EquivalentCourse, keys map[string]bool) []schema.EquivalentCourse {
+	var courses []schema.EquivalentCourse
+	for _, course := range candidates {
+		if key := crossListKey(course.BasicCourse); key != "" && keys[key] {
+			courses = append(courses, course)
+		}
+	}
+	return courses
+}
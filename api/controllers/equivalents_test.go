@@ -0,0 +1,67 @@
+package controllers
+
+import (
+	"testing"
+
+	"github.com/UTDNebula/nebula-api/api/schema"
+)
+
+func TestCourseCode(t *testing.T) {
+	testCases := map[string]struct {
+		Prefix   string
+		Number   string
+		Expected string
+	}{
+		"Normal":     {Prefix: "CS", Number: "3345", Expected: "CS 3345"},
+		"Lowercase":  {Prefix: "cs", Number: "3345", Expected: "CS 3345"},
+		"Whitespace": {Prefix: " SE ", Number: "4485 ", Expected: "SE 4485"},
+		"Letters":    {Prefix: "hist", Number: "v3v1", Expected: "HIST V3V1"},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			if result := courseCode(tc.Prefix, tc.Number); result != tc.Expected {
+				t.Errorf("Expected %q, got %q", tc.Expected, result)
+			}
+		})
+	}
+}
+
+func TestCrossListKey(t *testing.T) {
+	cs := schema.BasicCourse{Subject_prefix: "CS", Course_number: "4485", Title: "Computer Science Project"}
+	se := schema.BasicCourse{Subject_prefix: "SE", Course_number: "4485", Title: "computer  science project "}
+	other := schema.BasicCourse{Subject_prefix: "SE", Course_number: "4485", Title: "Software Engineering Project"}
+
+	if crossListKey(cs) != crossListKey(se) {
+		t.Errorf("Expected %q and %q to be cross-listed", crossListKey(cs), crossListKey(se))
+	}
+	if crossListKey(cs) == crossListKey(other) {
+		t.Errorf("Expected courses with different titles not to be cross-listed")
+	}
+
+	nextYear := cs
+	nextYear.Catalog_year = "25"
+	if crossListKey(cs) == crossListKey(nextYear) {
+		t.Errorf("Expected courses of different catalog years not to be cross-listed")
+	}
+	for _, generic := range []schema.BasicCourse{
+		{Subject_prefix: "CS", Course_number: "4V98", Title: "Undergraduate Internship"},
+		{Subject_prefix: "CS", Course_number: "4390", Title: "Independent Study in Computer Science"},
+		{Subject_prefix: "PSY", Course_number: "4396", Title: "Special Topics in Psychology"},
+	} {
+		if key := crossListKey(generic); key != "" {
+			t.Errorf("Expected %s %s %q not to be cross-listed by title, got %q", generic.Subject_prefix, generic.Course_number, generic.Title, key)
+		}
+	}
+}
+
+func TestCrossListedCourses(t *testing.T) {
+	cs := schema.EquivalentCourse{BasicCourse: schema.BasicCourse{Subject_prefix: "CS", Course_number: "4485", Title: "Computer Science Project"}}
+	se := schema.EquivalentCourse{BasicCourse: schema.BasicCourse{Subject_prefix: "SE", Course_number: "4485", Title: "computer science project "}}
+	other := schema.EquivalentCourse{BasicCourse: schema.BasicCourse{Subject_prefix: "SE", Course_number: "4485", Title: "Software Engineering Project"}}
+
+	courses := crossListedCourses([]schema.EquivalentCourse{cs, se, other}, map[string]bool{crossListKey(cs.BasicCourse): true})
+	if len(courses) != 2 || courses[0].Subject_prefix != "CS" || courses[1].Subject_prefix != "SE" {
+		t.Errorf("Expected CS 4485 and SE 4485 with differently cased titles, got %v", courses)
+	}
+}
@@ -0,0 +1,151 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/UTDNebula/nebula-api/api/schema"
+
+	"github.com/gin-gonic/gin"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Maximum number of days a date range request can cover
+const maxEventRangeDays = 31
+
+// eventDateParam parses the date path parameter of the event endpoints.
+// Automatically responds with an error if it isn't an ISO date.
+func eventDateParam(c *gin.Context) (time.Time, error) {
+	day, err := parseEventDate(c.Param("date"))
+	if err != nil {
+		respond(c, http.StatusBadRequest, "error", err.Error())
+		return day, err
+	}
+	return day, nil
+}
+
+// eventWindowQuery parses the start and end query parameters into a time window on day, returning nil if neither is given.
+// Either defaults to the corresponding end of the day.
+// Automatically responds with an error if either isn't a time or the window is empty.
+func eventWindowQuery(c *gin.Context, day time.Time) (*schema.TimeRange, error) {
+	start, end := c.Query("start"), c.Query("end")
+	if start == "" && end == "" {
+		return nil, nil
+	}
+
+	window := schema.TimeRange{Start: day, End: day.AddDate(0, 0, 1)}
+	if start != "" {
+		minutes, err := schema.ParseClockTime(start)
+		if err != nil {
+			respond(c, http.StatusBadRequest, "error", err.Error())
+			return nil, err
+		}
+		window.Start = atMinutes(day, minutes)
+	}
+	if end != "" {
+		minutes, err := schema.ParseClockTime(end)
+		if err != nil {
+			respond(c, http.StatusBadRequest, "error", err.Error())
+			return nil, err
+		}
+		window.End = atMinutes(day, minutes)
+	}
+	if !window.End.After(window.Start) {
+		err := errors.New("end must be after start")
+		respond(c, http.StatusBadRequest, "error", err.Error())
+		return nil, err
+	}
+	return &window, nil
+}
+
+// eventRangeQuery validates the from and to query parameters of the event range endpoints, returning every date between them inclusive.
+// Automatically responds with an error if either isn't an ISO date or the range is empty or too long.
+func eventRangeQuery(c *gin.Context) ([]string, error) {
+	from, err := parseEventDate(c.Query("from"))
+	if err != nil {
+		respond(c, http.StatusBadRequest, "error", "from: "+err.Error())
+		return nil, err
+	}
+	to, err := parseEventDate(c.Query("to"))
+	if err != nil {
+		respond(c, http.StatusBadRequest, "error", "to: "+err.Error())
+		return nil, err
+	}
+
+	dates, err := eventRangeDates(from, to)
+	if err != nil {
+		respond(c, http.StatusBadRequest, "error", err.Error())
+		return nil, err
+	}
+	return dates, nil
+}
+
+// eventRangeDates lists the ISO dates from one day to another inclusive, up to the maximum range
+func eventRangeDates(from time.Time, to time.Time) ([]string, error) {
+	if to.Before(from) {
+		return nil, errors.New("to must not be before from")
+	}
+	var dates []string
+	for day := from; !day.After(to); day = day.AddDate(0, 0, 1) {
+		if len(dates) == maxEventRangeDays {
+			return nil, fmt.Errorf("date ranges can cover at most %d days", maxEventRangeDays)
+		}
+		dates = append(dates, day.Format(time.DateOnly))
+	}
+	return dates, nil
+}
+
+// findRangeEvents retrieves the events of every date from one of the event collections in a single query.
+// Returns one entry per date in order, with no buildings for dates which have no events.
+func findRangeEvents[T any](ctx context.Context, collection *mongo.Collection, dates []string) ([]schema.MultiBuildingEvents[T], error) {
+	var found []schema.MultiBuildingEvents[T]
+	cursor, err := collection.Find(ctx, bson.M{"date": bson.M{"$in": dates}})
+	if err != nil {
+		return nil, err
+	}
+	if err = cursor.All(ctx, &found); err != nil {
+		return nil, err
+	}
+
+	byDate := make(map[string][]schema.MultiBuildingEvents[T], len(found))
+	for _, events := range found {
+		byDate[events.Date] = append(byDate[events.Date], events)
+	}
+
+	days := make([]schema.MultiBuildingEvents[T], 0, len(dates))
+	for _, date := range dates {
+		days = append(days, mergeEvents(date, byDate[date]...))
+	}
+	return days, nil
+}
+
+// respondWithEventRange responds with the events of every date in the range given by the query from an event source, filtered by the query parameters
+func respondWithEventRange[T any](c *gin.Context, source eventSource[T]) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	dates, err := eventRangeQuery(c)
+	if err != nil {
+		return
+	}
+
+	days, err := findRangeEvents[T](ctx, source.collection, dates)
+	if err != nil {
+		respondWithInternalError(c, err)
+		return
+	}
+
+	for i, events := range days {
+		day, _ := parseEventDate(events.Date) // dates of the range are always valid
+		if days[i], err = source.requestedEvents(c, day, events); err != nil {
+			return
+		}
+	}
+
+	respond(c, http.StatusOK, "success", days)
+}
@@ -0,0 +1,109 @@
+package controllers
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/UTDNebula/nebula-api/api/schema"
+)
+
+// Maximum number of buildings and rooms suggested when a lookup fails
+const (
+	maxSuggestedBuildings = 10
+	maxSuggestedRooms     = 20
+)
+
+// lookupBuilding finds the events of a building case-insensitively.
+// The error suggests the available buildings if it can't be found.
+func lookupBuilding[T any](events schema.MultiBuildingEvents[T], building string) (schema.SingleBuildingEvents[T], error) {
+	building = strings.TrimSpace(building)
+	names := make([]string, 0, len(events.Buildings))
+	for _, b := range events.Buildings {
+		if strings.EqualFold(strings.TrimSpace(b.Building), building) {
+			return b, nil
+		}
+		names = append(names, strings.TrimSpace(b.Building))
+	}
+	return schema.SingleBuildingEvents[T]{}, errors.New("Building not found. Available: " + suggestNames(names, maxSuggestedBuildings))
+}
+
+// lookupRoom finds the events of a room in a building case-insensitively.
+// The error suggests the available rooms if it can't be found.
+func lookupRoom[T any](building schema.SingleBuildingEvents[T], room string) (schema.RoomEvents[T], error) {
+	room = strings.TrimSpace(room)
+	names := make([]string, 0, len(building.Rooms))
+	for _, r := range building.Rooms {
+		if strings.EqualFold(strings.TrimSpace(r.Room), room) {
+			return r, nil
+		}
+		names = append(names, strings.TrimSpace(r.Room))
+	}
+	return schema.RoomEvents[T]{}, errors.New("Room not found. Available in this building: " + suggestNames(names, maxSuggestedRooms))
+}
+
+// suggestNames lists up to limit names, noting when there are more
+func suggestNames(names []string, limit int) string {
+	if len(names) > limit {
+		names = append(names[:limit:limit], "(and more)")
+	}
+	return strings.Join(names, ", ")
+}
+
+// filterEvents keeps only the events for which keep returns true, leaving every building and room in place
+func filterEvents[T any](events schema.MultiBuildingEvents[T], keep func(event T) bool) schema.MultiBuildingEvents[T] {
+	filtered := schema.MultiBuildingEvents[T]{Date: events.Date, Buildings: make([]schema.SingleBuildingEvents[T], 0, len(events.Buildings))}
+	for _, building := range events.Buildings {
+		rooms := make([]schema.RoomEvents[T], 0, len(building.Rooms))
+		for _, room := range building.Rooms {
+			kept := make([]T, 0, len(room.Events))
+			for _, event := range room.Events {
+				if keep(event) {
+					kept = append(kept, event)
+				}
+			}
+			rooms = append(rooms, schema.RoomEvents[T]{Room: room.Room, Events: kept})
+		}
+		filtered.Buildings = append(filtered.Buildings, schema.SingleBuildingEvents[T]{Building: building.Building, Rooms: rooms})
+	}
+	return filtered
+}
+
+// eventsInWindow keeps only the events taking place during part of a time window, dropping those whose span can't be determined
+func eventsInWindow[T any](events schema.MultiBuildingEvents[T], day time.Time, window schema.TimeRange, span eventSpan[T]) schema.MultiBuildingEvents[T] {
+	return filterEvents(events, func(event T) bool {
+		start, end, ok := span(day, event)
+		return ok && start.Before(window.End) && end.After(window.Start)
+	})
+}
+
+// mergeEvents combines event hierarchies into one for the given date.
+// Buildings and rooms whose names only differ in case and surrounding spaces are merged, in the order they first appear.
+func mergeEvents[T any](date string, hierarchies ...schema.MultiBuildingEvents[T]) schema.MultiBuildingEvents[T] {
+	merged := schema.MultiBuildingEvents[T]{Date: date, Buildings: []schema.SingleBuildingEvents[T]{}}
+	buildingIndexes := make(map[string]int)
+	roomIndexes := make(map[string]int)
+	for _, events := range hierarchies {
+		for _, building := range events.Buildings {
+			name := strings.TrimSpace(building.Building)
+			i, ok := buildingIndexes[strings.ToUpper(name)]
+			if !ok {
+				i = len(merged.Buildings)
+				buildingIndexes[strings.ToUpper(name)] = i
+				merged.Buildings = append(merged.Buildings, schema.SingleBuildingEvents[T]{Building: name, Rooms: []schema.RoomEvents[T]{}})
+			}
+
+			for _, room := range building.Rooms {
+				roomName := strings.TrimSpace(room.Room)
+				j, ok := roomIndexes[roomKey(name, roomName)]
+				if !ok {
+					j = len(merged.Buildings[i].Rooms)
+					roomIndexes[roomKey(name, roomName)] = j
+					merged.Buildings[i].Rooms = append(merged.Buildings[i].Rooms, schema.RoomEvents[T]{Room: roomName, Events: []T{}})
+				}
+				merged.Buildings[i].Rooms[j].Events = append(merged.Buildings[i].Rooms[j].Events, room.Events...)
+			}
+		}
+	}
+	return merged
+}
@@ -0,0 +1,127 @@
+package controllers
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/UTDNebula/nebula-api/api/schema"
+)
+
+func TestLookupBuildingAndRoom(t *testing.T) {
+	events := schema.MultiBuildingEvents[string]{
+		Date: "2025-09-02",
+		Buildings: []schema.SingleBuildingEvents[string]{
+			{Building: "ECSS", Rooms: []schema.RoomEvents[string]{{Room: "2.415", Events: []string{"Lecture"}}}},
+			{Building: " JSOM ", Rooms: []schema.RoomEvents[string]{{Room: "1.118"}}},
+		},
+	}
+
+	building, err := lookupBuilding(events, " ecss")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	room, err := lookupRoom(building, "2.415")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !slices.Equal(room.Events, []string{"Lecture"}) {
+		t.Errorf("Expected [Lecture], got %v", room.Events)
+	}
+
+	if _, err = lookupBuilding(events, "SCI"); err == nil || err.Error() != "Building not found. Available: ECSS, JSOM" {
+		t.Errorf("Expected building suggestions, got %v", err)
+	}
+	if _, err = lookupRoom(building, "1.118"); err == nil || err.Error() != "Room not found. Available in this building: 2.415" {
+		t.Errorf("Expected room suggestions, got %v", err)
+	}
+	if suggestions := suggestNames([]string{"A", "B", "C"}, 2); suggestions != "A, B, (and more)" {
+		t.Errorf("Expected truncated suggestions, got %s", suggestions)
+	}
+}
+
+func TestMergeEvents(t *testing.T) {
+	first := schema.MultiBuildingEvents[string]{
+		Date: "2025-09-02",
+		Buildings: []schema.SingleBuildingEvents[string]{
+			{Building: "ECSS", Rooms: []schema.RoomEvents[string]{{Room: "2.415", Events: []string{"Lecture"}}}},
+			{Building: "ecss ", Rooms: []schema.RoomEvents[string]{{Room: "2.415", Events: []string{"Lab"}}, {Room: "2.410", Events: []string{"Exam"}}}},
+		},
+	}
+	second := schema.MultiBuildingEvents[string]{
+		Date:      "2025-09-02",
+		Buildings: []schema.SingleBuildingEvents[string]{{Building: "JSOM", Rooms: []schema.RoomEvents[string]{{Room: "1.118", Events: []string{"Seminar"}}}}},
+	}
+
+	merged := mergeEvents("2025-09-02", first, second)
+	if len(merged.Buildings) != 2 || merged.Buildings[0].Building != "ECSS" || merged.Buildings[1].Building != "JSOM" {
+		t.Fatalf("Expected buildings ECSS and JSOM, got %v", merged.Buildings)
+	}
+	rooms := merged.Buildings[0].Rooms
+	if len(rooms) != 2 || !slices.Equal(rooms[0].Events, []string{"Lecture", "Lab"}) || !slices.Equal(rooms[1].Events, []string{"Exam"}) {
+		t.Errorf("Expected rooms 2.415 and 2.410 to be merged, got %v", rooms)
+	}
+
+	if empty := mergeEvents[string]("2025-09-03"); empty.Date != "2025-09-03" || empty.Buildings == nil || len(empty.Buildings) != 0 {
+		t.Errorf("Expected no buildings, got %v", empty)
+	}
+}
+
+func TestEventsInWindow(t *testing.T) {
+	day := time.Date(2025, 9, 2, 0, 0, 0, 0, campusLocation)
+	events := schema.MultiBuildingEvents[schema.SectionWithTime]{
+		Date: "2025-09-02",
+		Buildings: []schema.SingleBuildingEvents[schema.SectionWithTime]{{Building: "ECSS", Rooms: []schema.RoomEvents[schema.SectionWithTime]{
+			{Room: "2.415", Events: []schema.SectionWithTime{
+				{StartTime: "10:00am", EndTime: "11:15am"},
+				{StartTime: "1:00pm", EndTime: "2:15pm"},
+				{StartTime: "TBA", EndTime: "TBA"},
+			}},
+			{Room: "2.410", Events: []schema.SectionWithTime{{StartTime: "4:00pm", EndTime: "5:15pm"}}},
+		}}},
+	}
+	window := schema.TimeRange{Start: atMinutes(day, 11*60), End: atMinutes(day, 14*60)}
+
+	filtered := eventsInWindow(events, day, window, sectionEventSpan)
+	rooms := filtered.Buildings[0].Rooms
+	if len(rooms) != 2 {
+		t.Fatalf("Expected both rooms to remain, got %v", rooms)
+	}
+	if len(rooms[0].Events) != 2 || rooms[0].Events[0].StartTime != "10:00am" || rooms[0].Events[1].StartTime != "1:00pm" {
+		t.Errorf("Expected the two overlapping events, got %v", rooms[0].Events)
+	}
+	if len(rooms[1].Events) != 0 {
+		t.Errorf("Expected no events, got %v", rooms[1].Events)
+	}
+	if len(events.Buildings[0].Rooms[0].Events) != 3 {
+		t.Errorf("Expected the original events to be left unchanged")
+	}
+}
+
+func TestTimelineHierarchy(t *testing.T) {
+	events := []schema.UnifiedEvent{
+		{Title: "Lecture", Building: "ecss", Room: "2.415"},
+		{Title: "Meeting", Building: "SSA", Room: "13.330"},
+	}
+	known := []schema.BuildingRooms{
+		{Building: "ECSS", Rooms: []schema.Room{{Room: "2.415"}, {Room: "2.412"}}},
+		{Building: "JSOM", Rooms: []schema.Room{{Room: "1.118"}}},
+	}
+	hierarchy := timelineHierarchy("2025-09-02", events, known)
+
+	room, err := lookupRoom(hierarchy.Buildings[0], "2.415")
+	if err != nil || len(room.Events) != 1 || room.Events[0].Title != "Lecture" {
+		t.Errorf("Expected the lecture in ECSS 2.415, got %v (%v)", room, err)
+	}
+	// Known buildings and rooms are found even without events
+	building, err := lookupBuilding(hierarchy, "jsom")
+	if err != nil || len(building.Rooms) != 1 || len(building.Rooms[0].Events) != 0 {
+		t.Errorf("Expected JSOM without events, got %v (%v)", building, err)
+	}
+	if _, err := lookupBuilding(hierarchy, "SSA"); err != nil {
+		t.Errorf("Expected buildings with events to be found even if unknown, got %v", err)
+	}
+	if _, err := lookupBuilding(hierarchy, "SCI"); err == nil || err.Error() != "Building not found. Available: ECSS, JSOM, SSA" {
+		t.Errorf("Expected building suggestions, got %v", err)
+	}
+}
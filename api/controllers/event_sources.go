@@ -0,0 +1,212 @@
+package controllers
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"github.com/UTDNebula/nebula-api/api/schema"
+)
+
+// eventSpan determines when an event on the given day starts and ends, returning false if it can't be determined
+type eventSpan[T any] func(day time.Time, event T) (time.Time, time.Time, bool)
+
+// eventFilter parses the query parameters specific to an event source into a filter of its events, returning nil if none are given.
+// Automatically responds with an error if the parameters are invalid.
+type eventFilter[T any] func(c *gin.Context) (func(event T) bool, error)
+
+// An event collection along with how to tell when its events take place and how to filter them
+type eventSource[T any] struct {
+	collection *mongo.Collection
+	span       eventSpan[T]
+	filter     eventFilter[T] // nil if the source has no filters
+}
+
+var (
+	sectionEventSource       = eventSource[schema.SectionWithTime]{collection: eventsCollection, span: sectionEventSpan}
+	astraEventSource         = eventSource[schema.AstraEvent]{collection: astraCollection, span: astraEventSpan, filter: astraEventFilter}
+	mazevoEventSource        = eventSource[schema.MazevoEvent]{collection: mazevoCollection, span: mazevoEventSpan, filter: mazevoEventFilter}
+	cometCalendarEventSource = eventSource[schema.Event]{collection: cometCalendarCollection, span: cometCalendarEventSpan}
+)
+
+// Spans of the events of each source, whose times are stored differently
+func sectionEventSpan(day time.Time, event schema.SectionWithTime) (time.Time, time.Time, bool) {
+	return parseEventSpan(day, event.StartTime, event.EndTime)
+}
+
+func astraEventSpan(day time.Time, event schema.AstraEvent) (time.Time, time.Time, bool) {
+	return parseEventSpan(day, stringValue(event.StartDate), stringValue(event.EndDate))
+}
+
+// astraOccupancySpan is the span of an Astra booking if it occupies its room, which cancelled and denied bookings don't
+func astraOccupancySpan(day time.Time, event schema.AstraEvent) (time.Time, time.Time, bool) {
+	if astraStateVacant(event.State) {
+		return time.Time{}, time.Time{}, false
+	}
+	return astraEventSpan(day, event)
+}
+
+// astraStateVacant reports whether bookings in the state leave their rooms free
+func astraStateVacant(state schema.AstraState) bool {
+	return state == schema.AstraStateCancelled || state == schema.AstraStateDenied
+}
+
+func mazevoEventSpan(day time.Time, event schema.MazevoEvent) (time.Time, time.Time, bool) {
+	if event.Start != nil && event.End != nil && event.End.After(*event.Start) {
+		return *event.Start, *event.End, true
+	}
+	return parseEventSpan(day, stringValue(event.DateTimeStart), stringValue(event.DateTimeEnd))
+}
+
+// Mazevo statuses of events which don't occupy their rooms, matched as lowercase substrings
+var mazevoVacantStatuses = []string{"cancel", "denied", "declined"}
+
+// mazevoOccupancySpan is the span a Mazevo event occupies its room including setup and teardown,
+// if it occupies the room at all, which cancelled and denied events don't
+func mazevoOccupancySpan(day time.Time, event schema.MazevoEvent) (time.Time, time.Time, bool) {
+	if mazevoStatusVacant(stringValue(event.StatusDescription)) {
+		return time.Time{}, time.Time{}, false
+	}
+	if event.OccupiedStart != nil && event.OccupiedEnd != nil {
+		return *event.OccupiedStart, *event.OccupiedEnd, true
+	}
+	return mazevoEventSpan(day, event)
+}
+
+// mazevoStatusVacant reports whether events with the status leave their rooms free
+func mazevoStatusVacant(status string) bool {
+	status = strings.ToLower(status)
+	return slices.ContainsFunc(mazevoVacantStatuses, func(vacant string) bool { return strings.Contains(status, vacant) })
+}
+
+func cometCalendarEventSpan(_ time.Time, event schema.Event) (time.Time, time.Time, bool) {
+	return event.StartTime, event.EndTime, !event.StartTime.IsZero() && event.EndTime.After(event.StartTime)
+}
+
+// The event endpoints of every source behave the same way:
+//   - an invalid date or time window is a 400
+//   - a date without events has no buildings
+//   - a building or room that doesn't have events on the date is a 404 suggesting the ones that do
+
+// respondWithDayEvents responds with the events of the date parameter from an event source
+func respondWithDayEvents[T any](c *gin.Context, source eventSource[T]) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	events, err := findRequestedEvents(ctx, c, source)
+	if err != nil {
+		return
+	}
+
+	respond(c, http.StatusOK, "success", events)
+}
+
+// respondWithBuildingEvents responds with the events of the date parameter from an event source in the building parameter
+func respondWithBuildingEvents[T any](c *gin.Context, source eventSource[T]) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	events, err := findRequestedEvents(ctx, c, source)
+	if err != nil {
+		return
+	}
+	building, err := findRequestedBuilding(c, events)
+	if err != nil {
+		return
+	}
+
+	respond(c, http.StatusOK, "success", building)
+}
+
+// respondWithRoomEvents responds with the events of the date parameter from an event source in the building and room parameters
+func respondWithRoomEvents[T any](c *gin.Context, source eventSource[T]) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	events, err := findRequestedEvents(ctx, c, source)
+	if err != nil {
+		return
+	}
+	room, err := findRequestedRoom(c, events)
+	if err != nil {
+		return
+	}
+
+	respond(c, http.StatusOK, "success", room)
+}
+
+// findRequestedEvents retrieves the events of the date parameter from an event source, filtered by the query parameters.
+// Automatically responds with an error if the parameters are invalid or the events can't be retrieved.
+func findRequestedEvents[T any](ctx context.Context, c *gin.Context, source eventSource[T]) (schema.MultiBuildingEvents[T], error) {
+	day, err := eventDateParam(c)
+	if err != nil {
+		return schema.MultiBuildingEvents[T]{}, err
+	}
+
+	events, err := findDayEvents[T](ctx, source.collection, day.Format(time.DateOnly))
+	if err != nil {
+		respondWithInternalError(c, err)
+		return events, err
+	}
+	return source.requestedEvents(c, day, events)
+}
+
+// requestedEvents limits the events of a day to the time window of the start and end query parameters if given,
+// and to those matching the filters specific to the source.
+// Automatically responds with an error if the parameters are invalid.
+func (source eventSource[T]) requestedEvents(c *gin.Context, day time.Time, events schema.MultiBuildingEvents[T]) (schema.MultiBuildingEvents[T], error) {
+	window, err := eventWindowQuery(c, day)
+	if err != nil {
+		return events, err
+	}
+	if window != nil {
+		events = eventsInWindow(events, day, *window, source.span)
+	}
+
+	if source.filter != nil {
+		keep, err := source.filter(c)
+		if err != nil {
+			return events, err
+		}
+		if keep != nil {
+			events = filterEvents(events, keep)
+		}
+	}
+	return events, nil
+}
+
+// findRequestedBuilding finds the events of the building parameter.
+// Automatically responds with an error suggesting the available buildings if it can't be found.
+func findRequestedBuilding[T any](c *gin.Context, events schema.MultiBuildingEvents[T]) (schema.SingleBuildingEvents[T], error) {
+	building, err := lookupBuilding(events, c.Param("building"))
+	if err != nil {
+		if len(events.Buildings) == 0 {
+			respond(c, http.StatusNotFound, "error", "No events found for the specified date")
+		} else {
+			respond(c, http.StatusNotFound, "error", err.Error())
+		}
+		return building, err
+	}
+	return building, nil
+}
+
+// findRequestedRoom finds the events of the building and room parameters.
+// Automatically responds with an error suggesting the available buildings or rooms if either can't be found.
+func findRequestedRoom[T any](c *gin.Context, events schema.MultiBuildingEvents[T]) (schema.RoomEvents[T], error) {
+	building, err := findRequestedBuilding(c, events)
+	if err != nil {
+		return schema.RoomEvents[T]{}, err
+	}
+	room, err := lookupRoom(building, strings.TrimSpace(c.Param("room")))
+	if err != nil {
+		respond(c, http.StatusNotFound, "error", err.Error())
+		return room, err
+	}
+	return room, nil
+}
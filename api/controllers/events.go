@@ -2,9 +2,7 @@ package controllers
 
 import (
 	"context"
-	"errors"
 	"net/http"
-	"strings" // adding missing import
 	"time"
 
 	"github.com/UTDNebula/nebula-api/api/configs"
@@ -26,29 +24,29 @@ var eventsCollection *mongo.Collection = configs.GetCollection("events")
 // @Description	"Returns all sections with meetings on the specified date"
 // @Produce		json
 // @Param			date	path		string																	true	"ISO date of the set of events to get"
+// @Param			start	query		string																	false	"Only include events ending after this time (e.g. 2:00pm or 14:00)"
+// @Param			end		query		string																	false	"Only include events starting before this time (e.g. 4:00pm or 16:00)"
 // @Success		200		{object}	schema.APIResponse[schema.MultiBuildingEvents[schema.SectionWithTime]]	"All sections with meetings on the specified date"
 // @Failure		500		{object}	schema.APIResponse[string]												"A string describing the error"
+// @Failure		400		{object}	schema.APIResponse[string]												"A string describing the error"
 func Events(c *gin.Context) {
-	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
-
-	date := c.Param("date")
-
-	var events schema.MultiBuildingEvents[schema.SectionWithTime]
-
-	defer cancel()
+	respondWithDayEvents(c, sectionEventSource)
+}
 
-	// find and parse matching date
-	err := eventsCollection.FindOne(ctx, bson.M{"date": date}).Decode(&events)
-	if err != nil {
-		if errors.Is(err, mongo.ErrNoDocuments) {
-			events.Date = date
-			events.Buildings = []schema.SingleBuildingEvents[schema.SectionWithTime]{}
-		} else {
-			respondWithInternalError(c, err)
-			return
-		}
-	}
-	respond(c, http.StatusOK, "success", events)
+// @Id				eventsRange
+// @Router			/events [get]
+// @Tags			Events
+// @Description	"Returns all sections with meetings on each date in the range, up to 31 days"
+// @Produce		json
+// @Param			from	query		string																		true	"ISO date of the first day of the range"
+// @Param			to		query		string																		true	"ISO date of the last day of the range, inclusive"
+// @Param			start	query		string																		false	"Only include events ending after this time (e.g. 2:00pm or 14:00)"
+// @Param			end		query		string																		false	"Only include events starting before this time (e.g. 4:00pm or 16:00)"
+// @Success		200		{object}	schema.APIResponse[[]schema.MultiBuildingEvents[schema.SectionWithTime]]	"All sections with meetings on each date in the range, one entry per day"
+// @Failure		500		{object}	schema.APIResponse[string]													"A string describing the error"
+// @Failure		400		{object}	schema.APIResponse[string]													"A string describing the error"
+func EventsRange(c *gin.Context) {
+	respondWithEventRange(c, sectionEventSource)
 }
 
 // @Id				eventsByBuilding
@@ -58,54 +56,14 @@ func Events(c *gin.Context) {
 // @Produce		json
 // @Param			date		path		string																	true	"ISO date of the set of events to get"
 // @Param			building	path		string																	true	"building abbreviation of event locations"
+// @Param			start		query		string																	false	"Only include events ending after this time (e.g. 2:00pm or 14:00)"
+// @Param			end			query		string																	false	"Only include events starting before this time (e.g. 4:00pm or 16:00)"
 // @Success		200			{object}	schema.APIResponse[schema.SingleBuildingEvents[schema.SectionWithTime]]	"All sections with meetings on the specified date in the specified building"
 // @Failure		500			{object}	schema.APIResponse[string]												"A string describing the error"
 // @Failure		404			{object}	schema.APIResponse[string]												"A string describing the error"
+// @Failure		400			{object}	schema.APIResponse[string]												"A string describing the error"
 func EventsByBuilding(c *gin.Context) {
-	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
-	defer cancel()
-
-	date := c.Param("date")
-	building := c.Param("building")
-
-	var events schema.MultiBuildingEvents[schema.SectionWithTime]
-	var eventsByBuilding schema.SingleBuildingEvents[schema.SectionWithTime]
-
-	// find and parse matching date
-	err := eventsCollection.FindOne(ctx, bson.M{"date": date}).Decode(&events)
-	if err != nil {
-		if errors.Is(err, mongo.ErrNoDocuments) {
-			events.Date = date
-			events.Buildings = []schema.SingleBuildingEvents[schema.SectionWithTime]{}
-		} else {
-			respondWithInternalError(c, err)
-			return
-		}
-	}
-
-	// case insensitive filter after data is retrieved
-	for _, b := range events.Buildings {
-		if strings.EqualFold(strings.TrimSpace(b.Building), building) {
-			eventsByBuilding = b
-			break
-		}
-	}
-
-	// if no building is found, return an err with suggestion
-	if eventsByBuilding.Building == "" {
-		maxBuildings := min(len(events.Buildings), 10)
-		var available []string
-		for i := range maxBuildings {
-			available = append(available, strings.TrimSpace(events.Buildings[i].Building))
-		}
-		if len(events.Buildings) > maxBuildings {
-			available = append(available, "(and more)")
-		}
-
-		respond(c, http.StatusNotFound, "error", "Building not found. Available: "+strings.Join(available, ", "))
-		return
-	}
-	respond(c, http.StatusOK, "success", eventsByBuilding)
+	respondWithBuildingEvents(c, sectionEventSource)
 }
 
 // @Id				eventsByRoom
@@ -116,67 +74,14 @@ func EventsByBuilding(c *gin.Context) {
 // @Param			date		path		string															true	"ISO date of the set of events to get"
 // @Param			building	path		string															true	"building abbreviation of the event location"
 // @Param			room		path		string															true	"room number"
+// @Param			start		query		string															false	"Only include events ending after this time (e.g. 2:00pm or 14:00)"
+// @Param			end			query		string															false	"Only include events starting before this time (e.g. 4:00pm or 16:00)"
 // @Success		200			{object}	schema.APIResponse[schema.RoomEvents[schema.SectionWithTime]]	"All sections with meetings on the specified date in the specified building and room"
 // @Failure		500			{object}	schema.APIResponse[string]										"A string describing the error"
 // @Failure		404			{object}	schema.APIResponse[string]										"A string describing the error"
+// @Failure		400			{object}	schema.APIResponse[string]										"A string describing the error"
 func EventsByRoom(c *gin.Context) {
-	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
-	defer cancel()
-
-	date := c.Param("date")
-	building := strings.TrimSpace(c.Param("building"))
-	room := strings.TrimSpace(c.Param("room"))
-
-	var events schema.MultiBuildingEvents[schema.SectionWithTime]
-	var eventsByRoom schema.RoomEvents[schema.SectionWithTime]
-
-	err := eventsCollection.FindOne(ctx, bson.M{"date": date}).Decode(&events)
-	if err != nil {
-		if errors.Is(err, mongo.ErrNoDocuments) {
-			respond(c, http.StatusNotFound, "error", "No events found for the specified date")
-			return
-		}
-		respondWithInternalError(c, err)
-		return
-	}
-
-	// 3. Updated to use Case-Insensitive matching for Building
-	var matchedBuilding *schema.SingleBuildingEvents[schema.SectionWithTime]
-	for _, b := range events.Buildings {
-		if strings.EqualFold(strings.TrimSpace(b.Building), building) {
-			matchedBuilding = &b
-			break
-		}
-	}
-
-	if matchedBuilding == nil {
-		respond(c, http.StatusNotFound, "error", "Building not found")
-		return
-	}
-
-	// 4. Updated to use Case-Insensitive matching for Room
-	for _, r := range matchedBuilding.Rooms {
-		if strings.EqualFold(strings.TrimSpace(r.Room), room) {
-			eventsByRoom = r
-			break
-		}
-	}
-
-	if eventsByRoom.Room == "" {
-		maxRooms := min(len(matchedBuilding.Rooms), 20)
-		var available []string
-		for i := range maxRooms {
-			available = append(available, strings.TrimSpace(matchedBuilding.Rooms[i].Room))
-		}
-		if len(matchedBuilding.Rooms) > maxRooms {
-			available = append(available, "(and more)")
-		}
-
-		respond(c, http.StatusNotFound, "error", "Room not found. Available in this building: "+strings.Join(available, ", "))
-		return
-	}
-
-	respond(c, http.StatusOK, "success", eventsByRoom)
+	respondWithRoomEvents(c, sectionEventSource)
 }
 
 // @Id				sectionsByRoomDetailed
@@ -187,57 +92,33 @@ func EventsByRoom(c *gin.Context) {
 // @Param			date		path		string													true	"ISO date of the set of events to get"
 // @Param			building	path		string													true	"building abbreviation of the event location"
 // @Param			room		path		string													true	"room number"
+// @Param			start		query		string													false	"Only include events ending after this time (e.g. 2:00pm or 14:00)"
+// @Param			end			query		string													false	"Only include events starting before this time (e.g. 4:00pm or 16:00)"
 // @Success		200			{object}	schema.APIResponse[schema.RoomEvents[schema.Section]]	"Full section objects with meetings on the specified date in the specified building and room"
 // @Failure		500			{object}	schema.APIResponse[string]								"A string describing the error"
 // @Failure		404			{object}	schema.APIResponse[string]								"A string describing the error"
+// @Failure		400			{object}	schema.APIResponse[string]								"A string describing the error"
 func SectionsByRoomDetailed(c *gin.Context) {
 	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
 	defer cancel()
 
-	date := c.Param("date")
-	building := strings.TrimSpace(c.Param("building"))
-	room := strings.TrimSpace(c.Param("room"))
-
-	var events schema.MultiBuildingEvents[schema.SectionWithTime]
-	var sectionsByRoom schema.RoomEvents[schema.Section]
-
-	// Find events for the specified date
-	err := eventsCollection.FindOne(ctx, bson.M{"date": date}).Decode(&events)
+	events, err := findRequestedEvents(ctx, c, sectionEventSource)
 	if err != nil {
-		if errors.Is(err, mongo.ErrNoDocuments) {
-			respond(c, http.StatusNotFound, "error", "No events found for the specified date")
-			return
-		}
-		respondWithInternalError(c, err)
 		return
 	}
-
-	// Extract section IDs for the specified building and room using case-insensitive matching
-	var sectionIDs []primitive.ObjectID
-	buildingFound := false
-	for _, b := range events.Buildings {
-		if strings.EqualFold(strings.TrimSpace(b.Building), building) {
-			buildingFound = true
-			for _, r := range b.Rooms {
-				if strings.EqualFold(strings.TrimSpace(r.Room), room) {
-					sectionsByRoom.Room = r.Room
-					for _, event := range r.Events {
-						sectionIDs = append(sectionIDs, event.Section)
-					}
-					break
-				}
-			}
-			break
-		}
-	}
-
-	if !buildingFound {
-		respond(c, http.StatusNotFound, "error", "Building not found")
+	room, err := findRequestedRoom(c, events)
+	if err != nil {
 		return
 	}
 
+	// Extract the section IDs of the room
+	sectionsByRoom := schema.RoomEvents[schema.Section]{Room: room.Room, Events: []schema.Section{}}
+	var sectionIDs []primitive.ObjectID
+	for _, event := range room.Events {
+		sectionIDs = append(sectionIDs, event.Section)
+	}
 	if len(sectionIDs) == 0 {
-		respond(c, http.StatusNotFound, "error", "No sections found for the specified building and room")
+		respond(c, http.StatusOK, "success", sectionsByRoom)
 		return
 	}
 
@@ -254,10 +135,5 @@ func SectionsByRoomDetailed(c *gin.Context) {
 		return
 	}
 
-	if len(sectionsByRoom.Events) == 0 {
-		respond(c, http.StatusNotFound, "error", "No section details found")
-		return
-	}
-
 	respond(c, http.StatusOK, "success", sectionsByRoom)
 }
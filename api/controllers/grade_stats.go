@@ -0,0 +1,135 @@
+package controllers
+
+import (
+	"math"
+
+	"github.com/UTDNebula/nebula-api/api/schema"
+)
+
+// Grade distributions are ordered A+, A, A-, B+, B, B-, C+, C, C-, D+, D, D-, F, W.
+// gradePoints holds the points of each letter grade, W doesn't count towards the GPA.
+var gradePoints = [13]float64{4.0, 4.0, 3.67, 3.33, 3.0, 2.67, 2.33, 2.0, 1.67, 1.33, 1.0, 0.67, 0.0}
+
+// Number of pseudo-students at the prior GPA added when shrinking a GPA,
+// a distribution needs about this many graded students before its own GPA outweighs the prior
+const gradeShrinkageStrength = 20.0
+
+// Index of D+ in a grade distribution, every grade from here on counts towards the DFW rate
+const firstDFWGrade = 9
+
+// toGradeDistribution copies a section's grade distribution into its fixed size form, ignoring any extra entries
+func toGradeDistribution(grades []int) [14]int {
+	var distribution [14]int
+	copy(distribution[:], grades)
+	return distribution
+}
+
+// addGradeDistributions sums two grade distributions
+func addGradeDistributions(a [14]int, b [14]int) [14]int {
+	for i := range a {
+		a[i] += b[i]
+	}
+	return a
+}
+
+// gradeGPA returns the mean grade points of a distribution and the number of students who received a letter grade
+func gradeGPA(distribution [14]int) (float64, int) {
+	var points float64
+	var graded int
+	for i, weight := range gradePoints {
+		points += weight * float64(distribution[i])
+		graded += distribution[i]
+	}
+	if graded == 0 {
+		return 0, 0
+	}
+	return points / float64(graded), graded
+}
+
+// gradeDFWRate returns the fraction of all students in a distribution who received a D, F or W
+func gradeDFWRate(distribution [14]int) float64 {
+	var dfw, total int
+	for i, count := range distribution {
+		total += count
+		if i >= firstDFWGrade {
+			dfw += count
+		}
+	}
+	if total == 0 {
+		return 0
+	}
+	return float64(dfw) / float64(total)
+}
+
+// percentileRank returns the percentage of values below value, counting ties as half below
+func percentileRank(value float64, values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	var below, equal int
+	for _, v := range values {
+		if v < value {
+			below++
+		} else if v == value {
+			equal++
+		}
+	}
+	return 100 * (float64(below) + 0.5*float64(equal)) / float64(len(values))
+}
+
+// gradeStats computes the sample size, standard error and GPA shrunk toward the prior GPA of a distribution
+func gradeStats(distribution [14]int, sections int, prior float64) schema.GradeStats {
+	gpa, graded := gradeGPA(distribution)
+
+	var standardError float64
+	if graded > 1 {
+		var squares float64
+		for i, weight := range gradePoints {
+			squares += float64(distribution[i]) * (weight - gpa) * (weight - gpa)
+		}
+		variance := squares / float64(graded-1)
+		standardError = math.Sqrt(variance / float64(graded))
+	}
+
+	n := float64(graded)
+	return schema.GradeStats{
+		Sections:       sections,
+		Sample_size:    graded,
+		Gpa:            gpa,
+		Standard_error: standardError,
+		Prior_gpa:      prior,
+		Shrunk_gpa:     (n*gpa + gradeShrinkageStrength*prior) / (n + gradeShrinkageStrength),
+	}
+}
+
+// Groups of grades whose GPA a distribution is shrunk toward
+type gradePriorGroup int
+
+const (
+	priorCourse      gradePriorGroup = iota // every section of the course
+	priorCourseLevel                        // every course with the same prefix and level, e.g. CS 3xxx
+	priorPrefix                             // every course with the same prefix
+	priorAll                                // every graded section
+)
+
+// gradePrior picks the group a grades query is shrunk toward, always a wider group than the one being queried:
+// the course when the query narrows down a professor or section of it, the course's peers when it covers the whole course,
+// and everything else otherwise
+func gradePrior(flag string, prefix string, number string, sectionNumber string, professor bool) gradePriorGroup {
+	switch {
+	case flag == "section_endpoint":
+		return priorCourse
+	case flag == "course_endpoint":
+		return priorCourseLevel
+	case flag == "professor_endpoint":
+		return priorAll
+	case number != "" && (professor || sectionNumber != ""):
+		return priorCourse
+	case number != "":
+		return priorCourseLevel
+	case prefix != "" && professor:
+		return priorPrefix
+	default:
+		return priorAll
+	}
+}
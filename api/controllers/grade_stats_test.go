@@ -0,0 +1,158 @@
+package controllers
+
+import (
+	"math"
+	"testing"
+)
+
+func TestGradeGPA(t *testing.T) {
+	testCases := map[string]struct {
+		Distribution [14]int
+		GPA          float64
+		Graded       int
+	}{
+		"Empty":          {Distribution: [14]int{}, GPA: 0, Graded: 0},
+		"All A":          {Distribution: [14]int{0, 10}, GPA: 4, Graded: 10},
+		"A and F":        {Distribution: [14]int{5, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 5}, GPA: 2, Graded: 10},
+		"W not counted":  {Distribution: [14]int{0, 0, 0, 0, 4, 0, 0, 0, 0, 0, 0, 0, 0, 6}, GPA: 3, Graded: 4},
+		"Only withdrawn": {Distribution: [14]int{0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 6}, GPA: 0, Graded: 0},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			gpa, graded := gradeGPA(tc.Distribution)
+			if math.Abs(gpa-tc.GPA) > 1e-9 || graded != tc.Graded {
+				t.Errorf("Expected (%v, %d), got (%v, %d)", tc.GPA, tc.Graded, gpa, graded)
+			}
+		})
+	}
+}
+
+func TestGradeDFWRate(t *testing.T) {
+	distribution := [14]int{0, 6, 0, 0, 0, 0, 0, 0, 0, 1, 0, 0, 1, 2}
+	if rate := gradeDFWRate(distribution); math.Abs(rate-0.4) > 1e-9 {
+		t.Errorf("Expected DFW rate 0.4, got %v", rate)
+	}
+	if rate := gradeDFWRate([14]int{}); rate != 0 {
+		t.Errorf("Expected DFW rate 0 for an empty distribution, got %v", rate)
+	}
+}
+
+func TestPercentileRank(t *testing.T) {
+	values := []float64{1, 2, 2, 3}
+
+	testCases := map[string]struct {
+		Value    float64
+		Expected float64
+	}{
+		"Lowest":  {Value: 1, Expected: 12.5},
+		"Tied":    {Value: 2, Expected: 50},
+		"Highest": {Value: 3, Expected: 87.5},
+		"Outside": {Value: 4, Expected: 100},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			if result := percentileRank(tc.Value, values); result != tc.Expected {
+				t.Errorf("Expected %v, got %v", tc.Expected, result)
+			}
+		})
+	}
+}
+
+func TestRankGrades(t *testing.T) {
+	totals := map[string]*gradeTotals{
+		"easy":     {Name: "Easy", Sections: 2, Distribution: [14]int{0, 10}},
+		"hard":     {Name: "Hard", Sections: 1, Distribution: [14]int{0, 0, 0, 0, 0, 0, 0, 5, 0, 0, 0, 0, 3, 2}},
+		"ungraded": {Name: "Ungraded", Sections: 1, Distribution: [14]int{0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 3}},
+	}
+
+	rankings := rankGrades(totals)
+	if len(rankings) != 2 {
+		t.Fatalf("Expected 2 rankings, got %d", len(rankings))
+	}
+	if rankings[0].Id != "easy" || rankings[0].Rank != 1 || rankings[0].Gpa_percentile != 75 {
+		t.Errorf("Unexpected first ranking %+v", rankings[0])
+	}
+	if rankings[1].Id != "hard" || rankings[1].Rank != 2 || rankings[1].Students != 10 || rankings[1].Dfw_percentile != 75 {
+		t.Errorf("Unexpected second ranking %+v", rankings[1])
+	}
+}
+
+func TestGradeStats(t *testing.T) {
+	t.Run("Small sample shrinks toward prior", func(t *testing.T) {
+		stats := gradeStats([14]int{0, 5}, 1, 3)
+		if stats.Sample_size != 5 || stats.Gpa != 4 || stats.Standard_error != 0 {
+			t.Errorf("Unexpected stats %+v", stats)
+		}
+		// (5*4 + 20*3) / 25
+		if math.Abs(stats.Shrunk_gpa-3.2) > 1e-9 {
+			t.Errorf("Expected shrunk GPA 3.2, got %v", stats.Shrunk_gpa)
+		}
+	})
+
+	t.Run("Large sample keeps its GPA", func(t *testing.T) {
+		stats := gradeStats([14]int{0, 2000}, 40, 3)
+		if math.Abs(stats.Shrunk_gpa-4) > 0.05 {
+			t.Errorf("Expected shrunk GPA close to 4, got %v", stats.Shrunk_gpa)
+		}
+	})
+
+	t.Run("Standard error", func(t *testing.T) {
+		// Half A, half F: GPA 2, sample variance 4*10/9
+		stats := gradeStats([14]int{0, 5, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 5}, 2, 2)
+		expected := math.Sqrt(40.0 / 9.0 / 10.0)
+		if math.Abs(stats.Standard_error-expected) > 1e-9 {
+			t.Errorf("Expected standard error %v, got %v", expected, stats.Standard_error)
+		}
+		if stats.Sections != 2 || stats.Shrunk_gpa != 2 {
+			t.Errorf("Unexpected stats %+v", stats)
+		}
+	})
+
+	t.Run("No graded students", func(t *testing.T) {
+		stats := gradeStats([14]int{0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 4}, 1, 3.1)
+		if stats.Sample_size != 0 || stats.Shrunk_gpa != 3.1 {
+			t.Errorf("Unexpected stats %+v", stats)
+		}
+	})
+}
+
+func TestGradePrior(t *testing.T) {
+	testCases := map[string]struct {
+		Flag          string
+		Prefix        string
+		Number        string
+		SectionNumber string
+		Professor     bool
+		Expected      gradePriorGroup
+	}{
+		"Course":            {Flag: "overall", Prefix: "CS", Number: "3345", Expected: priorCourseLevel},
+		"CourseEndpoint":    {Flag: "course_endpoint", Expected: priorCourseLevel},
+		"CourseProfessor":   {Flag: "semester", Prefix: "CS", Number: "3345", Professor: true, Expected: priorCourse},
+		"CourseSection":     {Flag: "overall", Prefix: "CS", Number: "3345", SectionNumber: "001", Expected: priorCourse},
+		"SectionEndpoint":   {Flag: "section_endpoint", Expected: priorCourse},
+		"PrefixProfessor":   {Flag: "overall", Prefix: "CS", Professor: true, Expected: priorPrefix},
+		"Prefix":            {Flag: "overall", Prefix: "CS", Expected: priorAll},
+		"Professor":         {Flag: "overall", Professor: true, Expected: priorAll},
+		"ProfessorEndpoint": {Flag: "professor_endpoint", Expected: priorAll},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			if result := gradePrior(tc.Flag, tc.Prefix, tc.Number, tc.SectionNumber, tc.Professor); result != tc.Expected {
+				t.Errorf("Expected prior group %d, got %d", tc.Expected, result)
+			}
+		})
+	}
+
+	// A whole course is shrunk toward its peers rather than its own GPA
+	course := [14]int{0, 8, 0, 0, 2}
+	peers := addGradeDistributions(course, [14]int{0, 0, 0, 0, 0, 0, 0, 30, 0, 0, 0, 0, 10})
+	gpa, _ := gradeGPA(course)
+	prior, _ := gradeGPA(peers)
+	stats := gradeStats(course, 2, prior)
+	if stats.Prior_gpa == stats.Gpa || stats.Shrunk_gpa >= gpa || stats.Shrunk_gpa <= prior {
+		t.Errorf("Expected the GPA %v to be shrunk toward the peer GPA %v, got %+v", gpa, prior, stats)
+	}
+}
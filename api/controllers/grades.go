@@ -4,6 +4,8 @@ import (
 	"context"
 	"errors"
 	"net/http"
+	"regexp"
+	"strconv"
 	"time"
 
 	"github.com/UTDNebula/nebula-api/api/schema"
@@ -13,8 +15,12 @@ import (
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
+// GPAs of the groups grades are shrunk toward, which only change when new grades are published
+var priorGPACache = newTTLCache[string, float64](6*time.Hour, 1000)
+
 // We want to Filter (Match) ASAP
 
 // --------------------------------------------------------
@@ -54,16 +60,18 @@ import (
 // @Id				gradeAggregationBySemester
 // @Router			/grades/semester [get]
 // @Tags			Grades
-// @Description	"Returns grade distributions aggregated by semester"
+// @Description	"Returns grade distributions aggregated by semester, optionally each with its sample size, standard error and GPA shrunk toward the mean of the course, or of its peer courses when querying the whole course"
 // @Produce		json
-// @Param			prefix			query		string									false	"The course's subject prefix"
-// @Param			number			query		string									false	"The course's official number"
-// @Param			first_name		query		string									false	"The professor's first name"
-// @Param			last_name		query		string									false	"The professors's last name"
-// @Param			section_number	query		string									false	"The number of the section"
-// @Success		200				{object}	schema.APIResponse[[]schema.GradeData]	"An array of grade distributions for each semester included"
-// @Failure		500				{object}	schema.APIResponse[string]				"A string describing the error"
-// @Failure		400				{object}	schema.APIResponse[string]				"A string describing the error"
+// @Param			prefix				query		string									false	"The course's subject prefix"
+// @Param			number				query		string									false	"The course's official number"
+// @Param			first_name			query		string									false	"The professor's first name"
+// @Param			last_name			query		string									false	"The professors's last name"
+// @Param			section_number		query		string									false	"The number of the section"
+// @Param			merge_equivalents	query		boolean									false	"Whether to include the grades of cross-listed, renumbered and aliased equivalents of the course"
+// @Param			stats				query		boolean									false	"Whether to include the sample size, standard error and shrunk GPA of each semester"
+// @Success		200					{object}	schema.APIResponse[[]schema.GradeData]	"An array of grade distributions for each semester included"
+// @Failure		500					{object}	schema.APIResponse[string]				"A string describing the error"
+// @Failure		400					{object}	schema.APIResponse[string]				"A string describing the error"
 func GradeAggregationSemester(c *gin.Context) {
 	gradesAggregation("semester", c)
 }
@@ -71,16 +79,17 @@ func GradeAggregationSemester(c *gin.Context) {
 // @Id				gradeAggregationSectionType
 // @Router			/grades/semester/sectionType [get]
 // @Tags			Grades
-// @Description	"Returns the grade distributions aggregated by semester and broken down into section type"
+// @Description	"Returns the grade distributions aggregated by semester and broken down into section type, without stats"
 // @Produce		json
-// @Param			prefix			query		string										false	"The course's subject prefix"
-// @Param			number			query		string										false	"The course's official number"
-// @Param			first_name		query		string										false	"The professor's first name"
-// @Param			last_name		query		string										false	"The professors's last name"
-// @Param			section_number	query		string										false	"The number of the section"
-// @Success		200				{object}	schema.APIResponse[[]schema.TypedGradeData]	"An array of grade distributions for each section type for each semester included"
-// @Failure		500				{object}	schema.APIResponse[string]					"A string describing the error"
-// @Failure		400				{object}	schema.APIResponse[string]					"A string describing the error"
+// @Param			prefix				query		string										false	"The course's subject prefix"
+// @Param			number				query		string										false	"The course's official number"
+// @Param			first_name			query		string										false	"The professor's first name"
+// @Param			last_name			query		string										false	"The professors's last name"
+// @Param			section_number		query		string										false	"The number of the section"
+// @Param			merge_equivalents	query		boolean										false	"Whether to include the grades of cross-listed, renumbered and aliased equivalents of the course"
+// @Success		200					{object}	schema.APIResponse[[]schema.TypedGradeData]	"An array of grade distributions for each section type for each semester included"
+// @Failure		500					{object}	schema.APIResponse[string]					"A string describing the error"
+// @Failure		400					{object}	schema.APIResponse[string]					"A string describing the error"
 func GradesAggregationSectionType(c *gin.Context) {
 	gradesAggregation("section_type", c)
 }
@@ -90,14 +99,16 @@ func GradesAggregationSectionType(c *gin.Context) {
 // @Tags			Grades
 // @Description	"Returns the overall grade distribution"
 // @Produce		json
-// @Param			prefix			query		string						false	"The course's subject prefix"
-// @Param			number			query		string						false	"The course's official number"
-// @Param			first_name		query		string						false	"The professor's first name"
-// @Param			last_name		query		string						false	"The professors's last name"
-// @Param			section_number	query		string						false	"The number of the section"
-// @Success		200				{object}	schema.APIResponse[[]int]	"A grade distribution array"
-// @Failure		500				{object}	schema.APIResponse[string]	"A string describing the error"
-// @Failure		400				{object}	schema.APIResponse[string]	"A string describing the error"
+// @Param			prefix				query		string						false	"The course's subject prefix"
+// @Param			number				query		string						false	"The course's official number"
+// @Param			first_name			query		string						false	"The professor's first name"
+// @Param			last_name			query		string						false	"The professors's last name"
+// @Param			section_number		query		string						false	"The number of the section"
+// @Param			merge_equivalents	query		boolean						false	"Whether to include the grades of cross-listed, renumbered and aliased equivalents of the course"
+// @Param			stats				query		boolean						false	"Whether to respond with a schema.GradeSummary including the sample size, standard error and shrunk GPA instead of the bare distribution"
+// @Success		200					{object}	schema.APIResponse[[]int]	"A grade distribution array"
+// @Failure		500					{object}	schema.APIResponse[string]	"A string describing the error"
+// @Failure		400					{object}	schema.APIResponse[string]	"A string describing the error"
 func GradesAggregationOverall(c *gin.Context) {
 	gradesAggregation("overall", c)
 }
@@ -107,10 +118,12 @@ func GradesAggregationOverall(c *gin.Context) {
 // @Tags			Courses
 // @Description	"Returns the overall grade distribution for a course"
 // @Produce		json
-// @Param			id	path		string						true	"ID of course to get grades for"
-// @Success		200	{object}	schema.APIResponse[[]int]	"A grade distribution array for the course"
-// @Failure		500	{object}	schema.APIResponse[string]	"A string describing the error"
-// @Failure		400	{object}	schema.APIResponse[string]	"A string describing the error"
+// @Param			id					path		string						true	"ID of course to get grades for"
+// @Param			merge_equivalents	query		boolean						false	"Whether to include the grades of cross-listed, renumbered and aliased equivalents of the course"
+// @Param			stats				query		boolean						false	"Whether to respond with a schema.GradeSummary including the sample size, standard error and shrunk GPA instead of the bare distribution"
+// @Success		200					{object}	schema.APIResponse[[]int]	"A grade distribution array for the course"
+// @Failure		500					{object}	schema.APIResponse[string]	"A string describing the error"
+// @Failure		400					{object}	schema.APIResponse[string]	"A string describing the error"
 func GradesByCourseID(c *gin.Context) {
 	gradesAggregation("course_endpoint", c)
 }
@@ -120,10 +133,11 @@ func GradesByCourseID(c *gin.Context) {
 // @Tags			Sections
 // @Description	"Returns the overall grade distribution for a section"
 // @Produce		json
-// @Param			id	path		string						true	"ID of section to get grades for"
-// @Success		200	{object}	schema.APIResponse[[]int]	"A grade distribution array for the section"
-// @Failure		500	{object}	schema.APIResponse[string]	"A string describing the error"
-// @Failure		400	{object}	schema.APIResponse[string]	"A string describing the error"
+// @Param			id		path		string						true	"ID of section to get grades for"
+// @Param			stats	query		boolean						false	"Whether to respond with a schema.GradeSummary including the sample size, standard error and shrunk GPA instead of the bare distribution"
+// @Success		200		{object}	schema.APIResponse[[]int]	"A grade distribution array for the section"
+// @Failure		500		{object}	schema.APIResponse[string]	"A string describing the error"
+// @Failure		400		{object}	schema.APIResponse[string]	"A string describing the error"
 func GradesBySectionID(c *gin.Context) {
 	gradesAggregation("section_endpoint", c)
 }
@@ -133,10 +147,11 @@ func GradesBySectionID(c *gin.Context) {
 // @Tags			Professors
 // @Description	"Returns the overall grade distribution for a professor"
 // @Produce		json
-// @Param			id	path		string						true	"ID of professor to get grades for"
-// @Success		200	{object}	schema.APIResponse[[]int]	"A grade distribution array for the professor"
-// @Failure		500	{object}	schema.APIResponse[string]	"A string describing the error"
-// @Failure		400	{object}	schema.APIResponse[string]	"A string describing the error"
+// @Param			id		path		string						true	"ID of professor to get grades for"
+// @Param			stats	query		boolean						false	"Whether to respond with a schema.GradeSummary including the sample size, standard error and shrunk GPA instead of the bare distribution"
+// @Success		200		{object}	schema.APIResponse[[]int]	"A grade distribution array for the professor"
+// @Failure		500		{object}	schema.APIResponse[string]	"A string describing the error"
+// @Failure		400		{object}	schema.APIResponse[string]	"A string describing the error"
 func GradesByProfessorID(c *gin.Context) {
 	gradesAggregation("professor_endpoint", c)
 }
@@ -154,7 +169,7 @@ func gradesAggregation(flag string, c *gin.Context) {
 
 	var sectionMatch bson.D
 	var courseMatch bson.D
-	var courseFind bson.D
+	var courseFind bson.M
 	var professorMatch bson.D
 	var professorFind bson.D
 
@@ -175,6 +190,7 @@ func gradesAggregation(flag string, c *gin.Context) {
 	section_number := c.Query("section_number")
 	first_name := c.Query("first_name")
 	last_name := c.Query("last_name")
+	mergeEquivalents := c.Query("merge_equivalents") == "true"
 
 	if flag == "course_endpoint" || flag == "section_endpoint" || flag == "professor_endpoint" {
 		// parse object id from id parameter
@@ -201,12 +217,25 @@ func gradesAggregation(flag string, c *gin.Context) {
 	}
 	internalCourseNumber := sampleCourse.Internal_course_number
 
+	// Filter for the courses being aggregated, widened to their equivalents when requested
+	courseFilter := bson.M{"internal_course_number": internalCourseNumber}
+	if flag == "course_endpoint" {
+		courseFilter = bson.M{"_id": objId}
+	}
+	if mergeEquivalents && (flag == "course_endpoint" || number != "") {
+		courseFilter, err = equivalentCoursesFilter(ctx, courseFilter)
+		if err != nil {
+			respondWithInternalError(c, err)
+			return
+		}
+	}
+
 	switch {
 	case flag == "course_endpoint":
 		// Filter on course ID, from the course endpoint
 		collection = courseCollection
 
-		courseMatch := bson.D{{Key: "$match", Value: bson.M{"_id": objId}}}
+		courseMatch := bson.D{{Key: "$match", Value: courseFilter}}
 		pipeline = mongo.Pipeline{courseMatch, lookupSectionsStage(), unwindSectionsStage(), projectGradeDistributionStage(flag, false), unwindGradeDistributionStage(), groupGradesStage(flag), sortGradesStage(flag), sumGradesStage(), groupGradeDistributionStage(flag)}
 
 	case flag == "section_endpoint":
@@ -235,7 +264,7 @@ func gradesAggregation(flag string, c *gin.Context) {
 		collection = courseCollection
 
 		// Query using internal_course_number of the documents
-		courseMatch := bson.D{{Key: "$match", Value: bson.M{"internal_course_number": internalCourseNumber}}}
+		courseMatch := bson.D{{Key: "$match", Value: courseFilter}}
 		pipeline = mongo.Pipeline{courseMatch, lookupSectionsStage(), unwindSectionsStage(), projectGradeDistributionStage(flag, false), unwindGradeDistributionStage(), groupGradesStage(flag), sortGradesStage(flag), sumGradesStage(), groupGradeDistributionStage(flag)}
 
 	case prefix != "" && number != "" && section_number != "" && !professor:
@@ -244,7 +273,7 @@ func gradesAggregation(flag string, c *gin.Context) {
 
 		// Here we query all the courses with the given internal_couse_number,
 		// and then filter on the section_number of those courses
-		courseMatch := bson.D{{Key: "$match", Value: bson.M{"internal_course_number": internalCourseNumber}}}
+		courseMatch := bson.D{{Key: "$match", Value: courseFilter}}
 		sectionMatch := bson.D{{Key: "$match", Value: bson.M{"sections.section_number": section_number}}}
 
 		pipeline = mongo.Pipeline{courseMatch, lookupSectionsStage(), unwindSectionsStage(), sectionMatch, projectGradeDistributionStage(flag, false), unwindGradeDistributionStage(), groupGradesStage(flag), sortGradesStage(flag), sumGradesStage(), groupGradeDistributionStage(flag)}
@@ -301,10 +330,10 @@ func gradesAggregation(flag string, c *gin.Context) {
 		// Get valid course ids
 		if number == "" {
 			// if only the prefix is provided, filter only on the prefix
-			courseFind = bson.D{{Key: "subject_prefix", Value: prefix}}
+			courseFind = bson.M{"subject_prefix": prefix}
 		} else {
-			// if both prefix and course_number are provided, filter on internal_course_number
-			courseFind = bson.D{{Key: "internal_course_number", Value: internalCourseNumber}}
+			// if both prefix and course_number are provided, filter on internal_course_number (or its equivalents)
+			courseFind = courseFilter
 		}
 
 		cursor, err = courseCollection.Find(ctx, courseFind)
@@ -371,17 +400,90 @@ func gradesAggregation(flag string, c *gin.Context) {
 		}
 	}
 
+	// combine all semester grade_distributions
+	overallResponse := [14]int{}
+	overallSections := 0
+	for _, sem := range grades {
+		overallResponse = addGradeDistributions(overallResponse, sem.GradeDistribution)
+		overallSections += sem.Sections
+	}
+
+	// The GPA is shrunk toward the GPA of a wider group of grades, see gradePrior
+	priorGPA := func() (float64, error) {
+		switch gradePrior(flag, prefix, number, section_number, professor) {
+		case priorCourse:
+			if flag == "section_endpoint" {
+				var section schema.Section
+				err := sectionCollection.FindOne(ctx, bson.M{"_id": objId}, options.FindOne().SetProjection(bson.M{"course_reference": 1})).Decode(&section)
+				var course schema.EquivalentCourse
+				if err == nil {
+					err = courseCollection.FindOne(ctx, bson.M{"_id": section.Course_reference}, options.FindOne().SetProjection(bson.M{"internal_course_number": 1})).Decode(&course)
+				}
+				if errors.Is(err, mongo.ErrNoDocuments) {
+					return sectionsGPA(ctx, bson.M{}, "all")
+				} else if err != nil {
+					return 0, err
+				}
+				return coursesGPA(ctx, bson.M{"internal_course_number": course.Internal_course_number}, "internal|"+course.Internal_course_number)
+			}
+			// Keyed by what courseFilter was built from
+			key := "course|" + prefix + "|" + number
+			if flag == "course_endpoint" {
+				key = "course|" + objId.Hex()
+			}
+			return coursesGPA(ctx, courseFilter, key+"|"+strconv.FormatBool(mergeEquivalents))
+		case priorCourseLevel:
+			peerPrefix, peerNumber := sampleCourse.Subject_prefix, sampleCourse.Course_number
+			if flag == "course_endpoint" {
+				var course schema.EquivalentCourse
+				err := courseCollection.FindOne(ctx, bson.M{"_id": objId}, options.FindOne().SetProjection(bson.M{"subject_prefix": 1, "course_number": 1})).Decode(&course)
+				if err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
+					return 0, err
+				}
+				peerPrefix, peerNumber = course.Subject_prefix, course.Course_number
+			}
+			if peerNumber == "" {
+				return sectionsGPA(ctx, bson.M{}, "all")
+			}
+			level := peerNumber[:1]
+			return coursesGPA(ctx, bson.M{
+				"subject_prefix": peerPrefix,
+				"course_number":  bson.M{"$regex": "^" + regexp.QuoteMeta(level)},
+			}, "level|"+peerPrefix+"|"+level)
+		case priorPrefix:
+			return coursesGPA(ctx, bson.M{"subject_prefix": prefix}, "prefix|"+prefix)
+		default:
+			return sectionsGPA(ctx, bson.M{}, "all")
+		}
+	}
+
 	switch flag {
 	case "overall", "course_endpoint", "section_endpoint", "professor_endpoint":
-		// combine all semester grade_distributions
-		overallResponse := [14]int{}
-		for _, sem := range grades {
-			for i, grade := range sem.GradeDistribution {
-				overallResponse[i] += grade
+		if c.Query("stats") == "true" {
+			prior, err := priorGPA()
+			if err != nil {
+				respondWithInternalError(c, err)
+				return
 			}
+			respond(c, http.StatusOK, "success", schema.GradeSummary{
+				GradeDistribution: overallResponse,
+				Stats:             gradeStats(overallResponse, overallSections, prior),
+			})
+			return
 		}
 		respond(c, http.StatusOK, "success", overallResponse)
 	case "semester":
+		if c.Query("stats") == "true" {
+			prior, err := priorGPA()
+			if err != nil {
+				respondWithInternalError(c, err)
+				return
+			}
+			for i := range grades {
+				stats := gradeStats(grades[i].GradeDistribution, grades[i].Sections, prior)
+				grades[i].Stats = &stats
+			}
+		}
 		respond(c, http.StatusOK, "success", grades)
 	case "section_type":
 		respond(c, http.StatusOK, "success", sectionTypeGrades)
@@ -468,6 +570,8 @@ func groupGradesStage(flag string) bson.D {
 		{Key: "$group", Value: bson.D{
 			{Key: "_id", Value: groupID},
 			{Key: "grades", Value: bson.D{{Key: "$push", Value: "$grade_distribution"}}},
+			// Each document is one section's count for this grade
+			{Key: "sections", Value: bson.D{{Key: "$sum", Value: 1}}},
 		}},
 	}
 }
@@ -510,6 +614,7 @@ func groupGradeDistributionStage(flag string) bson.D {
 			{Key: "grade_distribution", Value: bson.D{
 				{Key: "$push", Value: "$grades"},
 			}},
+			{Key: "sections", Value: bson.D{{Key: "$max", Value: "$sections"}}},
 		}},
 	}
 }
@@ -537,3 +642,66 @@ func groupSemesterGradeDistributionsStage() bson.D {
 		}},
 	}
 }
+
+// coursesGPA returns the GPA of every section of the courses matching the filter, caching it under cacheKey unless it's empty
+func coursesGPA(ctx context.Context, courseFilter bson.M, cacheKey string) (float64, error) {
+	if gpa, ok := priorGPACache.Get(cacheKey); ok {
+		return gpa, nil
+	}
+
+	var courses []schema.BasicCourse
+	cursor, err := courseCollection.Find(ctx, courseFilter, options.Find().SetProjection(bson.M{"_id": 1}))
+	if err != nil {
+		return 0, err
+	}
+	if err = cursor.All(ctx, &courses); err != nil {
+		return 0, err
+	}
+	courseIDs := make([]primitive.ObjectID, 0, len(courses))
+	for _, course := range courses {
+		courseIDs = append(courseIDs, course.Id)
+	}
+
+	gpa, err := sectionsGPA(ctx, bson.M{"course_reference": bson.M{"$in": courseIDs}}, "")
+	if err == nil && cacheKey != "" {
+		priorGPACache.Set(cacheKey, gpa)
+	}
+	return gpa, err
+}
+
+// sectionsGPA returns the GPA of the combined grade distributions of the sections matching the filter,
+// caching it under cacheKey unless it's empty
+func sectionsGPA(ctx context.Context, sectionFilter bson.M, cacheKey string) (float64, error) {
+	if gpa, ok := priorGPACache.Get(cacheKey); ok {
+		return gpa, nil
+	}
+
+	// Sum the count of each grade in the database rather than loading every section
+	var counts []struct {
+		Grade int `bson:"_id"`
+		Count int `bson:"count"`
+	}
+	cursor, err := sectionCollection.Aggregate(ctx, mongo.Pipeline{
+		bson.D{{Key: "$match", Value: sectionFilter}},
+		bson.D{{Key: "$unwind", Value: bson.D{{Key: "path", Value: "$grade_distribution"}, {Key: "includeArrayIndex", Value: "ix"}}}},
+		bson.D{{Key: "$group", Value: bson.D{{Key: "_id", Value: "$ix"}, {Key: "count", Value: bson.D{{Key: "$sum", Value: "$grade_distribution"}}}}}},
+	})
+	if err != nil {
+		return 0, err
+	}
+	if err = cursor.All(ctx, &counts); err != nil {
+		return 0, err
+	}
+
+	var distribution [14]int
+	for _, count := range counts {
+		if count.Grade >= 0 && count.Grade < len(distribution) {
+			distribution[count.Grade] = count.Count
+		}
+	}
+	gpa, _ := gradeGPA(distribution)
+	if cacheKey != "" {
+		priorGPACache.Set(cacheKey, gpa)
+	}
+	return gpa, nil
+}
@@ -1,14 +1,10 @@
 package controllers
 
 import (
-	"context"
-	"errors"
-	"net/http"
-	"time"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 
-	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
 
 	"github.com/UTDNebula/nebula-api/api/configs"
@@ -23,27 +19,87 @@ var mazevoCollection *mongo.Collection = configs.GetCollection("mazevo")
 // @Description	"Returns MazevoEvent based on the input date"
 // @Produce		json
 // @Param			date	path		string																true	"date (ISO format) to retrieve mazevo events"
+// @Param			start	query		string																false	"Only include events ending after this time (e.g. 2:00pm or 14:00)"
+// @Param			end		query		string																false	"Only include events starting before this time (e.g. 4:00pm or 16:00)"
+// @Param			status	query		string																false	"Only include events with these status descriptions, comma-separated (e.g. Confirmed)"
 // @Success		200		{object}	schema.APIResponse[schema.MultiBuildingEvents[schema.MazevoEvent]]	"All MazevoEvents with events on the inputted date"
 // @Failure		500		{object}	schema.APIResponse[string]											"A string describing the error"
+// @Failure		400		{object}	schema.APIResponse[string]											"A string describing the error"
 func MazevoEvents(c *gin.Context) {
-	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
-	defer cancel()
-
-	date := c.Param("date")
-
-	var mazevoEvents schema.MultiBuildingEvents[schema.MazevoEvent]
-
-	// Find mazevo event for input date
-	err := mazevoCollection.FindOne(ctx, bson.M{"date": date}).Decode(&mazevoEvents)
-	if err != nil {
-		if errors.Is(err, mongo.ErrNoDocuments) {
-			mazevoEvents.Date = date
-			mazevoEvents.Buildings = []schema.SingleBuildingEvents[schema.MazevoEvent]{}
-		} else {
-			respondWithInternalError(c, err)
-			return
-		}
-	}
+	respondWithDayEvents(c, mazevoEventSource)
+}
+
+// @Id				MazevoEventsRange
+// @Router			/mazevo [get]
+// @Tags			Events
+// @Description	"Returns MazevoEvents on each date in the range, up to 31 days"
+// @Produce		json
+// @Param			from	query		string																	true	"ISO date of the first day of the range"
+// @Param			to		query		string																	true	"ISO date of the last day of the range, inclusive"
+// @Param			start	query		string																	false	"Only include events ending after this time (e.g. 2:00pm or 14:00)"
+// @Param			end		query		string																	false	"Only include events starting before this time (e.g. 4:00pm or 16:00)"
+// @Param			status	query		string																	false	"Only include events with these status descriptions, comma-separated (e.g. Confirmed)"
+// @Success		200		{object}	schema.APIResponse[[]schema.MultiBuildingEvents[schema.MazevoEvent]]	"All MazevoEvents on each date in the range, one entry per day"
+// @Failure		500		{object}	schema.APIResponse[string]												"A string describing the error"
+// @Failure		400		{object}	schema.APIResponse[string]												"A string describing the error"
+func MazevoEventsRange(c *gin.Context) {
+	respondWithEventRange(c, mazevoEventSource)
+}
+
+// @Id				MazevoEventsByBuilding
+// @Router			/mazevo/{date}/{building} [get]
+// @Tags			Events
+// @Description	"Returns MazevoEvent based on the input date and building name"
+// @Produce		json
+// @Param			date		path		string																true	"date (ISO format) to retrieve mazevo events"
+// @Param			building	path		string																true	"building abbreviation of event locations"
+// @Param			start		query		string																false	"Only include events ending after this time (e.g. 2:00pm or 14:00)"
+// @Param			end			query		string																false	"Only include events starting before this time (e.g. 4:00pm or 16:00)"
+// @Param			status		query		string																false	"Only include events with these status descriptions, comma-separated (e.g. Confirmed)"
+// @Success		200			{object}	schema.APIResponse[schema.SingleBuildingEvents[schema.MazevoEvent]]	"All MazevoEvents on the specified date in the specified building"
+// @Failure		500			{object}	schema.APIResponse[string]											"A string describing the error"
+// @Failure		404			{object}	schema.APIResponse[string]											"A string describing the error"
+// @Failure		400			{object}	schema.APIResponse[string]											"A string describing the error"
+func MazevoEventsByBuilding(c *gin.Context) {
+	respondWithBuildingEvents(c, mazevoEventSource)
+}
 
-	respond(c, http.StatusOK, "success", mazevoEvents)
+// @Id				MazevoEventsByBuildingAndRoom
+// @Router			/mazevo/{date}/{building}/{room} [get]
+// @Tags			Events
+// @Description	"Returns MazevoEvent based on the input date building name and room number"
+// @Produce		json
+// @Param			date		path		string														true	"date (ISO format) to retrieve mazevo events"
+// @Param			building	path		string														true	"building abbreviation of event locations"
+// @Param			room		path		string														true	"room number for event"
+// @Param			start		query		string														false	"Only include events ending after this time (e.g. 2:00pm or 14:00)"
+// @Param			end			query		string														false	"Only include events starting before this time (e.g. 4:00pm or 16:00)"
+// @Param			status		query		string														false	"Only include events with these status descriptions, comma-separated (e.g. Confirmed)"
+// @Success		200			{object}	schema.APIResponse[schema.RoomEvents[schema.MazevoEvent]]	"All MazevoEvents on the specified date in the specified building and room"
+// @Failure		500			{object}	schema.APIResponse[string]									"A string describing the error"
+// @Failure		404			{object}	schema.APIResponse[string]									"A string describing the error"
+// @Failure		400			{object}	schema.APIResponse[string]									"A string describing the error"
+func MazevoEventsByBuildingAndRoom(c *gin.Context) {
+	respondWithRoomEvents(c, mazevoEventSource)
+}
+
+// mazevoEventFilter filters Mazevo events by the status descriptions of the status query parameter, ignoring case
+func mazevoEventFilter(c *gin.Context) (func(event schema.MazevoEvent) bool, error) {
+	value := c.Query("status")
+	if value == "" {
+		return nil, nil
+	}
+	var statuses []string
+	for _, status := range strings.Split(value, ",") {
+		statuses = append(statuses, strings.TrimSpace(status))
+	}
+	return func(event schema.MazevoEvent) bool {
+		status := strings.TrimSpace(stringValue(event.StatusDescription))
+		for _, allowed := range statuses {
+			if strings.EqualFold(status, allowed) {
+				return true
+			}
+		}
+		return false
+	}, nil
 }
@@ -0,0 +1,345 @@
+package controllers
+
+import (
+	"cmp"
+	"context"
+	"math"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/gin-gonic/gin"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"golang.org/x/text/unicode/norm"
+
+	"github.com/UTDNebula/nebula-api/api/configs"
+	"github.com/UTDNebula/nebula-api/api/schema"
+)
+
+// How long the professor name index is used before being rebuilt
+const professorIndexTTL = 6 * time.Hour
+
+// Ways a word of a name search can match a name, from closest to loosest
+const (
+	nameMatchExact    = "exact"
+	nameMatchNickname = "nickname"
+	nameMatchPrefix   = "prefix"
+	nameMatchFuzzy    = "fuzzy"
+)
+
+// Scores of each way a word can match, fuzzy matches losing a tenth for every edit past the first
+var nameMatchScores = map[string]float64{
+	nameMatchExact:    1,
+	nameMatchNickname: 0.9,
+	nameMatchPrefix:   0.8,
+	nameMatchFuzzy:    0.7,
+}
+
+// Words of the query matched against the wrong part of a name, such as "Smith John", score a little lower
+const swappedNamePenalty = 0.95
+
+// Groups of first names that refer to the same person, as name keys
+var nicknameGroups = [][]string{
+	{"robert", "bob", "bobby", "rob", "robbie", "bert"},
+	{"william", "bill", "billy", "will", "willie", "liam"},
+	{"richard", "rick", "ricky", "rich", "dick"},
+	{"james", "jim", "jimmy", "jamie"},
+	{"john", "jack", "johnny", "jon"},
+	{"jonathan", "jon", "jonny"},
+	{"michael", "mike", "mikey", "mick"},
+	{"elizabeth", "liz", "lizzie", "beth", "betty", "eliza", "lisa"},
+	{"katherine", "catherine", "kathryn", "kate", "kathy", "katie", "cathy", "kat"},
+	{"margaret", "maggie", "peggy", "meg", "margie"},
+	{"joseph", "joe", "joey"},
+	{"thomas", "tom", "tommy"},
+	{"charles", "charlie", "chuck", "chas"},
+	{"daniel", "dan", "danny"},
+	{"david", "dave", "davey"},
+	{"edward", "ed", "eddie", "ted", "ned"},
+	{"steven", "stephen", "steve"},
+	{"christopher", "chris", "topher"},
+	{"christine", "christina", "chris", "tina", "chrissy"},
+	{"matthew", "matt"},
+	{"anthony", "tony"},
+	{"andrew", "andy", "drew"},
+	{"benjamin", "ben", "benny"},
+	{"nicholas", "nick", "nicky"},
+	{"samuel", "sam", "sammy"},
+	{"samantha", "sam", "sammy"},
+	{"alexander", "alex", "al", "xander"},
+	{"alexandra", "alex", "lexi", "sandra"},
+	{"jennifer", "jen", "jenny"},
+	{"patricia", "pat", "patty", "trish"},
+	{"patrick", "pat", "paddy"},
+	{"susan", "sue", "susie", "suzy"},
+	{"deborah", "debra", "deb", "debbie"},
+	{"rebecca", "becky", "becca"},
+	{"kimberly", "kim"},
+	{"timothy", "tim", "timmy"},
+	{"gregory", "greg"},
+	{"jeffrey", "geoffrey", "jeff", "geoff"},
+	{"kenneth", "ken", "kenny"},
+	{"ronald", "ron", "ronnie"},
+	{"donald", "don", "donnie"},
+	{"lawrence", "laurence", "larry"},
+	{"raymond", "ray"},
+	{"gerald", "jerry"},
+	{"peter", "pete"},
+	{"philip", "phillip", "phil"},
+	{"douglas", "doug"},
+	{"frederick", "fred", "freddie"},
+	{"henry", "hank", "harry"},
+	{"zachary", "zach", "zack"},
+	{"victoria", "vicky", "tori"},
+	{"abigail", "abby"},
+	{"amanda", "mandy"},
+	{"jacqueline", "jackie"},
+	{"pamela", "pam"},
+	{"cynthia", "cindy"},
+	{"barbara", "barb"},
+	{"theodore", "ted", "teddy", "theo"},
+	{"nathaniel", "nathan", "nate"},
+	{"vincent", "vince", "vinny"},
+}
+
+// Nicknames by name key, built from the nickname groups
+var nicknames = buildNicknames(nicknameGroups)
+
+// The name of a professor in the name index
+type indexedProfessor struct {
+	id    primitive.ObjectID
+	first []string // name keys of the words of the first name
+	last  []string // name keys of the words of the last name
+}
+
+// An in-process index of every professor's name
+var professorIndex = newRefreshingIndex(professorIndexTTL, buildProfessorIndex)
+
+// @Id				professorNameSearch
+// @Router			/professor/search [get]
+// @Tags			Professors
+// @Description	"Returns paginated list of professors ranked by how closely their name matches the search string, ignoring case and accents and tolerating typos and nicknames (e.g. Bob for Robert). Every word of the search string has to match part of the name."
+// @Produce		json
+// @Param			q		query		string											true	"Name to search for, such as a last name or a first and last name"
+// @Param			offset	query		number											false	"The starting position of the current page of professors (e.g. For starting at the 17th professor, offset=16)."
+// @Success		200		{object}	schema.APIResponse[[]schema.ProfessorCandidate]	"A list of professors, closest match first"
+// @Failure		500		{object}	schema.APIResponse[string]						"A string describing the error"
+// @Failure		400		{object}	schema.APIResponse[string]						"A string describing the error"
+func ProfessorNameSearch(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	words := nameKeys(c.Query("q"))
+	if len(words) == 0 {
+		respond(c, http.StatusBadRequest, "error", "q is required")
+		return
+	}
+	var offset int
+	if value := c.Query("offset"); value != "" {
+		var err error
+		if offset, err = strconv.Atoi(value); err != nil || offset < 0 {
+			respond(c, http.StatusBadRequest, "offset is not type integer", value)
+			return
+		}
+	}
+
+	index, err := professorIndex.Get(ctx)
+	if err != nil {
+		respondWithInternalError(c, err)
+		return
+	}
+	matches := matchProfessorNames(index, words)
+	matches = matches[min(offset, len(matches)):]
+	matches = matches[:min(int(configs.GetEnvLimit()), len(matches))]
+
+	ids := make([]primitive.ObjectID, len(matches))
+	for i, match := range matches {
+		ids[i] = match.id
+	}
+	var professors []schema.Professor
+	if len(ids) > 0 {
+		cursor, err := professorCollection.Find(ctx, bson.M{"_id": bson.M{"$in": ids}})
+		if err != nil {
+			respondWithInternalError(c, err)
+			return
+		}
+		if err = cursor.All(ctx, &professors); err != nil {
+			respondWithInternalError(c, err)
+			return
+		}
+	}
+	byId := make(map[primitive.ObjectID]schema.Professor, len(professors))
+	for _, professor := range professors {
+		byId[professor.Id] = professor
+	}
+
+	candidates := make([]schema.ProfessorCandidate, 0, len(matches))
+	for _, match := range matches {
+		if professor, ok := byId[match.id]; ok {
+			candidates = append(candidates, schema.ProfessorCandidate{Professor: professor, Score: match.score, Match: match.match})
+		}
+	}
+
+	respond(c, http.StatusOK, "success", candidates)
+}
+
+// buildProfessorIndex extracts the name keys of every professor
+func buildProfessorIndex(ctx context.Context) ([]indexedProfessor, error) {
+	var professors []schema.BasicProfessor
+	cursor, err := professorCollection.Find(ctx, bson.M{}, options.Find().SetProjection(bson.M{"first_name": 1, "last_name": 1}))
+	if err != nil {
+		return nil, err
+	}
+	if err = cursor.All(ctx, &professors); err != nil {
+		return nil, err
+	}
+
+	index := make([]indexedProfessor, 0, len(professors))
+	for _, professor := range professors {
+		index = append(index, indexedProfessor{id: professor.Id, first: nameKeys(professor.First_name), last: nameKeys(professor.Last_name)})
+	}
+	return index, nil
+}
+
+// nameKeys normalizes a name into its words, lowercase and without accents or punctuation,
+// so that "José O'Brien-Smith" becomes jose, obrien and smith
+func nameKeys(name string) []string {
+	var key strings.Builder
+	for _, r := range norm.NFD.String(strings.ToLower(name)) {
+		switch {
+		case unicode.Is(unicode.Mn, r), r == '\'', r == '’', r == '.':
+			// Accents are dropped along with apostrophes and periods, which join rather than separate parts of names
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			key.WriteRune(r)
+		default:
+			key.WriteRune(' ')
+		}
+	}
+	return strings.Fields(key.String())
+}
+
+// buildNicknames maps each name in the groups to the other names of its groups
+func buildNicknames(groups [][]string) map[string][]string {
+	nicknames := make(map[string][]string)
+	for _, group := range groups {
+		for _, name := range group {
+			for _, other := range group {
+				if other != name && !slices.Contains(nicknames[name], other) {
+					nicknames[name] = append(nicknames[name], other)
+				}
+			}
+		}
+	}
+	return nicknames
+}
+
+// A professor whose name matches a search
+type professorNameMatch struct {
+	id    primitive.ObjectID
+	score float64
+	match string
+}
+
+// matchProfessorNames ranks the professors whose names match every word of the search, closest first
+func matchProfessorNames(index []indexedProfessor, words []string) []professorNameMatch {
+	var matches []professorNameMatch
+	for _, professor := range index {
+		score, match := professorNameScore(professor, words)
+		if score > 0 {
+			matches = append(matches, professorNameMatch{id: professor.id, score: score, match: match})
+		}
+	}
+	slices.SortStableFunc(matches, func(a, b professorNameMatch) int {
+		return cmp.Compare(b.score, a.score)
+	})
+	return matches
+}
+
+// professorNameScore rates how closely a professor's name matches the words of a search from 0 to 1, also returning the loosest way a word matched.
+// A single word can match any part of the name. With more, the first is expected to be the first name and the last the last name,
+// though they're also tried the other way around.
+func professorNameScore(professor indexedProfessor, words []string) (float64, string) {
+	all := append(slices.Clone(professor.first), professor.last...)
+	if len(words) == 1 {
+		return nameWordsScore(words, func(int) ([]string, []string) { return all, professor.first })
+	}
+
+	ordered, orderedMatch := nameWordsScore(words, func(i int) ([]string, []string) {
+		switch i {
+		case 0:
+			return professor.first, professor.first
+		case len(words) - 1:
+			return professor.last, nil
+		}
+		return all, professor.first
+	})
+	swapped, swappedMatch := nameWordsScore(words, func(i int) ([]string, []string) {
+		switch i {
+		case 0:
+			return professor.last, nil
+		case len(words) - 1:
+			return professor.first, professor.first
+		}
+		return all, professor.first
+	})
+	if swapped*swappedNamePenalty > ordered {
+		return math.Round(swapped*swappedNamePenalty*10000) / 10000, swappedMatch
+	}
+	return math.Round(ordered*10000) / 10000, orderedMatch
+}
+
+// nameWordsScore averages the best match of each word against the parts of the name it may match,
+// nicknames only counting for first names. Returns 0 unless every word matches.
+func nameWordsScore(words []string, candidates func(i int) (names []string, firstNames []string)) (float64, string) {
+	total := 0.0
+	loosest := nameMatchExact
+	for i, word := range words {
+		names, firstNames := candidates(i)
+		best, bestMatch := 0.0, ""
+		for _, name := range names {
+			score, match := nameWordScore(word, name, slices.Contains(firstNames, name))
+			if score > best {
+				best, bestMatch = score, match
+			}
+		}
+		if best == 0 {
+			return 0, ""
+		}
+		total += best
+		if nameMatchScores[bestMatch] < nameMatchScores[loosest] {
+			loosest = bestMatch
+		}
+	}
+	return total / float64(len(words)), loosest
+}
+
+// nameWordScore rates how closely a word of a search matches a word of a name
+func nameWordScore(word string, name string, firstName bool) (float64, string) {
+	switch {
+	case word == name:
+		return nameMatchScores[nameMatchExact], nameMatchExact
+	case firstName && slices.Contains(nicknames[word], name):
+		return nameMatchScores[nameMatchNickname], nameMatchNickname
+	case len(word) >= 2 && strings.HasPrefix(name, word):
+		return nameMatchScores[nameMatchPrefix], nameMatchPrefix
+	}
+
+	// Longer words tolerate more typos, and short ones none so they don't match everything
+	allowed := 0
+	switch length := len([]rune(word)); {
+	case length >= 8:
+		allowed = 2
+	case length >= 4:
+		allowed = 1
+	}
+	if distance := editDistance(word, name); distance > 0 && distance <= allowed {
+		return nameMatchScores[nameMatchFuzzy] - 0.1*float64(distance-1), nameMatchFuzzy
+	}
+	return 0, ""
+}
@@ -0,0 +1,59 @@
+package controllers
+
+import (
+	"slices"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestNameKeys(t *testing.T) {
+	if keys := nameKeys("  José O'Brien-Smith Jr. "); !slices.Equal(keys, []string{"jose", "obrien", "smith", "jr"}) {
+		t.Errorf("Expected jose, obrien, smith and jr, got %v", keys)
+	}
+	if keys := nameKeys("Zoë  Ångström"); !slices.Equal(keys, []string{"zoe", "angstrom"}) {
+		t.Errorf("Expected zoe and angstrom, got %v", keys)
+	}
+}
+
+func TestMatchProfessorNames(t *testing.T) {
+	professor := func(first string, last string) indexedProfessor {
+		return indexedProfessor{id: primitive.NewObjectID(), first: nameKeys(first), last: nameKeys(last)}
+	}
+	robert := professor("Robert", "Smith")
+	jose := professor("José", "Núñez")
+	roberta := professor("Roberta", "Smithson")
+	bob := professor("Bob", "Jones")
+	index := []indexedProfessor{robert, jose, roberta, bob}
+
+	testCases := map[string]struct {
+		Query    string
+		Expected []primitive.ObjectID
+		Match    string // of the first match
+	}{
+		"Exact":        {Query: "Robert Smith", Expected: []primitive.ObjectID{robert.id, roberta.id}, Match: nameMatchExact},
+		"Nickname":     {Query: "bob smith", Expected: []primitive.ObjectID{robert.id}, Match: nameMatchNickname},
+		"Accents":      {Query: "jose nunez", Expected: []primitive.ObjectID{jose.id}, Match: nameMatchExact},
+		"Typo":         {Query: "Robert Smiht", Expected: []primitive.ObjectID{robert.id}, Match: nameMatchFuzzy},
+		"Swapped":      {Query: "Smith, Robert", Expected: []primitive.ObjectID{robert.id, roberta.id}, Match: nameMatchExact},
+		"LastName":     {Query: "smith", Expected: []primitive.ObjectID{robert.id, roberta.id}, Match: nameMatchExact},
+		"NicknameOnly": {Query: "robert", Expected: []primitive.ObjectID{robert.id, bob.id, roberta.id}, Match: nameMatchExact},
+		"NoMatch":      {Query: "Alice Smith", Expected: nil},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			matches := matchProfessorNames(index, nameKeys(tc.Query))
+			var ids []primitive.ObjectID
+			for _, match := range matches {
+				ids = append(ids, match.id)
+			}
+			if !slices.Equal(ids, tc.Expected) {
+				t.Fatalf("Expected %v, got %v", tc.Expected, matches)
+			}
+			if len(matches) > 0 && matches[0].match != tc.Match {
+				t.Errorf("Expected the first match to be %s, got %s", tc.Match, matches[0].match)
+			}
+		})
+	}
+}
@@ -0,0 +1,306 @@
+package controllers
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"net/http"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/UTDNebula/nebula-api/api/schema"
+
+	"github.com/gin-gonic/gin"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Maximum number of cached rankings, as the peer group and academic session come from the query
+const maxCachedRankings = 1000
+
+// Rankings only change when new grades are published, so they are cached per peer group and academic session
+var rankingsCache = newTTLCache[string, schema.GradeRankings](6*time.Hour, maxCachedRankings)
+
+// Course levels are the first digit of the course number, e.g. 3 for 3000-level courses
+var courseLevelRegex = regexp.MustCompile(`^[0-9]$`)
+
+// Fields of a section needed to rank its grades
+var rankingSectionProjection = options.Find().SetProjection(bson.M{
+	"course_reference":   1,
+	"professors":         1,
+	"grade_distribution": 1,
+})
+
+// Running totals of the grades of one entity in a peer group
+type gradeTotals struct {
+	Name         string
+	Sections     int
+	Distribution [14]int
+}
+
+// @Id				gradeRankingsProfessors
+// @Router			/grades/rankings/professors [get]
+// @Tags			Grades
+// @Description	"Returns the professors who have taught the given course ranked by GPA, with GPA and DFW percentiles among all instructors of the course"
+// @Produce		json
+// @Param			prefix				query		string										true	"The course's subject prefix"
+// @Param			number				query		string										true	"The course's official number"
+// @Param			academic_session	query		string										false	"Only rank grades from this academic session (e.g. 24F)"
+// @Param			merge_equivalents	query		boolean										false	"Whether to include the grades of cross-listed, renumbered and aliased equivalents of the course"
+// @Param			order				query		string										false	"Set to asc to list the lowest GPA first"
+// @Success		200					{object}	schema.APIResponse[schema.GradeRankings]	"The professors of the course ranked by GPA"
+// @Failure		500					{object}	schema.APIResponse[string]					"A string describing the error"
+// @Failure		404					{object}	schema.APIResponse[string]					"A string describing the error"
+// @Failure		400					{object}	schema.APIResponse[string]					"A string describing the error"
+func ProfessorRankings(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	prefix := strings.ToUpper(strings.TrimSpace(c.Query("prefix")))
+	number := strings.ToUpper(strings.TrimSpace(c.Query("number")))
+	session := strings.TrimSpace(c.Query("academic_session"))
+	mergeEquivalents := c.Query("merge_equivalents") == "true"
+
+	if prefix == "" || number == "" {
+		respond(c, http.StatusBadRequest, "error", "prefix and number are required")
+		return
+	}
+
+	peerGroup := courseCode(prefix, number)
+	cacheKey := strings.Join([]string{"professors", peerGroup, session, strconv.FormatBool(mergeEquivalents)}, "|")
+
+	rankings, ok := rankingsCache.Get(cacheKey)
+	if !ok {
+		// Find every version of the course through its internal course number, as the grades endpoints do
+		var sampleCourse schema.EquivalentCourse
+		err := courseCollection.FindOne(ctx, bson.M{"subject_prefix": prefix, "course_number": number}).Decode(&sampleCourse)
+		if err != nil {
+			if errors.Is(err, mongo.ErrNoDocuments) {
+				respond(c, http.StatusNotFound, "error", "No courses with given prefix and number")
+			} else {
+				respondWithInternalError(c, err)
+			}
+			return
+		}
+
+		courseFilter := bson.M{"internal_course_number": sampleCourse.Internal_course_number}
+		if mergeEquivalents {
+			if courseFilter, err = equivalentCoursesFilter(ctx, courseFilter); err != nil {
+				respondWithInternalError(c, err)
+				return
+			}
+		}
+		courses, err := findEquivalentCandidates(ctx, courseFilter)
+		if err != nil {
+			respondWithInternalError(c, err)
+			return
+		}
+
+		sections, err := findRankingSections(ctx, courses, session)
+		if err != nil {
+			respondWithInternalError(c, err)
+			return
+		}
+
+		// Every professor of a section shares its grades
+		totals := make(map[string]*gradeTotals)
+		var professorIDs []primitive.ObjectID
+		for _, section := range sections {
+			for _, professorID := range section.Professors {
+				key := professorID.Hex()
+				if _, exists := totals[key]; !exists {
+					totals[key] = &gradeTotals{}
+					professorIDs = append(professorIDs, professorID)
+				}
+				totals[key].Sections++
+				totals[key].Distribution = addGradeDistributions(totals[key].Distribution, toGradeDistribution(section.Grade_distribution))
+			}
+		}
+
+		// Attach professor names
+		if len(professorIDs) > 0 {
+			var professors []schema.BasicProfessor
+			cursor, err := professorCollection.Find(ctx, bson.M{"_id": bson.M{"$in": professorIDs}})
+			if err != nil {
+				respondWithInternalError(c, err)
+				return
+			}
+			if err = cursor.All(ctx, &professors); err != nil {
+				respondWithInternalError(c, err)
+				return
+			}
+			for _, professor := range professors {
+				if total, exists := totals[professor.Id.Hex()]; exists {
+					total.Name = strings.TrimSpace(professor.First_name + " " + professor.Last_name)
+				}
+			}
+		}
+
+		rankings = schema.GradeRankings{Peer_group: peerGroup, Academic_session: session, Rankings: rankGrades(totals)}
+		rankingsCache.Set(cacheKey, rankings)
+	}
+
+	respondWithRankings(c, rankings)
+}
+
+// @Id				gradeRankingsCourses
+// @Router			/grades/rankings/courses [get]
+// @Tags			Grades
+// @Description	"Returns the courses with the given prefix and level ranked by GPA, with GPA and DFW percentiles among all courses of that prefix and level"
+// @Produce		json
+// @Param			prefix				query		string										true	"The courses' subject prefix"
+// @Param			level				query		string										true	"The first digit of the course numbers (e.g. 3 for 3000-level courses)"
+// @Param			academic_session	query		string										false	"Only rank grades from this academic session (e.g. 24F)"
+// @Param			order				query		string										false	"Set to asc to list the lowest GPA (hardest) courses first"
+// @Success		200					{object}	schema.APIResponse[schema.GradeRankings]	"The courses ranked by GPA"
+// @Failure		500					{object}	schema.APIResponse[string]					"A string describing the error"
+// @Failure		400					{object}	schema.APIResponse[string]					"A string describing the error"
+func CourseRankings(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	prefix := strings.ToUpper(strings.TrimSpace(c.Query("prefix")))
+	level := strings.TrimSpace(c.Query("level"))
+	session := strings.TrimSpace(c.Query("academic_session"))
+
+	if prefix == "" || !courseLevelRegex.MatchString(level) {
+		respond(c, http.StatusBadRequest, "error", "prefix and a single digit level are required")
+		return
+	}
+
+	peerGroup := prefix + " " + level + "xxx"
+	cacheKey := strings.Join([]string{"courses", peerGroup, session}, "|")
+
+	rankings, ok := rankingsCache.Get(cacheKey)
+	if !ok {
+		courses, err := findEquivalentCandidates(ctx, bson.M{
+			"subject_prefix": prefix,
+			"course_number":  bson.M{"$regex": "^" + level},
+		})
+		if err != nil {
+			respondWithInternalError(c, err)
+			return
+		}
+
+		sections, err := findRankingSections(ctx, courses, session)
+		if err != nil {
+			respondWithInternalError(c, err)
+			return
+		}
+
+		// Group the catalog years of a course together under its code
+		codes := make(map[primitive.ObjectID]string, len(courses))
+		totals := make(map[string]*gradeTotals)
+		for _, course := range courses {
+			code := courseCode(course.Subject_prefix, course.Course_number)
+			codes[course.Id] = code
+			if _, exists := totals[code]; !exists {
+				totals[code] = &gradeTotals{Name: course.Title}
+			}
+		}
+		for _, section := range sections {
+			total := totals[codes[section.Course_reference]]
+			if total == nil {
+				continue
+			}
+			total.Sections++
+			total.Distribution = addGradeDistributions(total.Distribution, toGradeDistribution(section.Grade_distribution))
+		}
+
+		rankings = schema.GradeRankings{Peer_group: peerGroup, Academic_session: session, Rankings: rankGrades(totals)}
+		rankingsCache.Set(cacheKey, rankings)
+	}
+
+	respondWithRankings(c, rankings)
+}
+
+// findRankingSections returns the sections of the given courses, optionally limited to one academic session
+func findRankingSections(ctx context.Context, courses []schema.EquivalentCourse, session string) ([]schema.Section, error) {
+	var sections []schema.Section
+
+	if len(courses) == 0 {
+		return sections, nil
+	}
+
+	courseIDs := make([]primitive.ObjectID, 0, len(courses))
+	for _, course := range courses {
+		courseIDs = append(courseIDs, course.Id)
+	}
+	sectionFilter := bson.M{"course_reference": bson.M{"$in": courseIDs}}
+	if session != "" {
+		sectionFilter["academic_session.name"] = session
+	}
+
+	cursor, err := sectionCollection.Find(ctx, sectionFilter, rankingSectionProjection)
+	if err != nil {
+		return nil, err
+	}
+	if err = cursor.All(ctx, &sections); err != nil {
+		return nil, err
+	}
+	return sections, nil
+}
+
+// rankGrades computes the GPA and DFW rate of every entity with graded students,
+// along with their percentiles in the peer group, sorted from highest to lowest GPA
+func rankGrades(totals map[string]*gradeTotals) []schema.GradeRanking {
+	rankings := make([]schema.GradeRanking, 0, len(totals))
+	for id, total := range totals {
+		gpa, graded := gradeGPA(total.Distribution)
+		if graded == 0 {
+			continue
+		}
+		students := 0
+		for _, count := range total.Distribution {
+			students += count
+		}
+		rankings = append(rankings, schema.GradeRanking{
+			Id:       id,
+			Name:     total.Name,
+			Sections: total.Sections,
+			Students: students,
+			Gpa:      gpa,
+			Dfw_rate: gradeDFWRate(total.Distribution),
+		})
+	}
+
+	gpas := make([]float64, len(rankings))
+	dfwRates := make([]float64, len(rankings))
+	for i, ranking := range rankings {
+		gpas[i] = ranking.Gpa
+		dfwRates[i] = ranking.Dfw_rate
+	}
+	for i := range rankings {
+		rankings[i].Gpa_percentile = percentileRank(rankings[i].Gpa, gpas)
+		rankings[i].Dfw_percentile = percentileRank(rankings[i].Dfw_rate, dfwRates)
+	}
+
+	// Break ties on the ID so the order is deterministic
+	slices.SortFunc(rankings, func(a, b schema.GradeRanking) int {
+		if byGpa := cmp.Compare(b.Gpa, a.Gpa); byGpa != 0 {
+			return byGpa
+		}
+		return strings.Compare(a.Id, b.Id)
+	})
+	for i := range rankings {
+		rankings[i].Rank = i + 1
+	}
+	return rankings
+}
+
+// respondWithRankings responds with the rankings in the order requested by the order query parameter
+func respondWithRankings(c *gin.Context, rankings schema.GradeRankings) {
+	if c.Query("order") == "asc" {
+		// Copy so the cached rankings keep their order
+		reversed := slices.Clone(rankings.Rankings)
+		slices.Reverse(reversed)
+		rankings.Rankings = reversed
+	}
+	respond(c, http.StatusOK, "success", rankings)
+}
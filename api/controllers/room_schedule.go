@@ -0,0 +1,214 @@
+package controllers
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/UTDNebula/nebula-api/api/schema"
+)
+
+// Fields of a section needed to expand its meetings
+var roomScheduleSectionProjection = options.Find().SetProjection(bson.M{"meetings": 1})
+
+// @Id				roomSchedule
+// @Router			/rooms/{building}/{room}/schedule [get]
+// @Tags			Events
+// @Description	"Returns the section meetings and the events from Astra, Mazevo and the Comet Calendar in a room on each day of a week, Monday first. Meetings are expanded from the sections themselves rather than the per-date events."
+// @Produce		json
+// @Param			building	path		string									true	"building abbreviation of the room"
+// @Param			room		path		string									true	"room number"
+// @Param			week		query		string									false	"ISO date of any day of the week, defaults to the current week"
+// @Success		200			{object}	schema.APIResponse[schema.RoomSchedule]	"Everything in the room on each day of the week"
+// @Failure		500			{object}	schema.APIResponse[string]				"A string describing the error"
+// @Failure		404			{object}	schema.APIResponse[string]				"A string describing the error"
+// @Failure		400			{object}	schema.APIResponse[string]				"A string describing the error"
+func RoomWeekSchedule(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	building := strings.TrimSpace(c.Param("building"))
+	room := strings.TrimSpace(c.Param("room"))
+
+	day := time.Now().In(campusLocation)
+	if value := c.Query("week"); value != "" {
+		var err error
+		if day, err = parseEventDate(value); err != nil {
+			respond(c, http.StatusBadRequest, "error", err.Error())
+			return
+		}
+	}
+	days := weekDays(day)
+	dates := make([]string, len(days))
+	for i, day := range days {
+		dates[i] = day.Format(time.DateOnly)
+	}
+
+	events, err := findRoomSectionEvents(ctx, building, room, days)
+	if err != nil {
+		respondWithInternalError(c, err)
+		return
+	}
+
+	astra, err := findRangeEvents[schema.AstraEvent](ctx, astraCollection, dates)
+	if err != nil {
+		respondWithInternalError(c, err)
+		return
+	}
+	events = append(events, collectRoomEvents(astra, sourceAstra, building, room, astraUnifiedEvent)...)
+
+	mazevo, err := findRangeEvents[schema.MazevoEvent](ctx, mazevoCollection, dates)
+	if err != nil {
+		respondWithInternalError(c, err)
+		return
+	}
+	events = append(events, collectRoomEvents(mazevo, sourceMazevo, building, room, mazevoUnifiedEvent)...)
+
+	calendar, err := findRangeEvents[schema.Event](ctx, cometCalendarCollection, dates)
+	if err != nil {
+		respondWithInternalError(c, err)
+		return
+	}
+	events = append(events, collectRoomEvents(calendar, sourceCometCalendar, building, room, cometCalendarUnifiedEvent)...)
+
+	// A room with nothing scheduled is only known if it's one of the schedulable rooms
+	if len(events) == 0 {
+		buildingRooms, err := findBuildingRooms(ctx, c, building)
+		if err != nil {
+			return
+		}
+		var rooms []string
+		for _, b := range buildingRooms {
+			for _, r := range b.Rooms {
+				rooms = append(rooms, strings.TrimSpace(r.Room))
+			}
+		}
+		if !slices.ContainsFunc(rooms, func(r string) bool { return strings.EqualFold(r, room) }) {
+			respond(c, http.StatusNotFound, "error", "Room not found. Available in this building: "+suggestNames(rooms, maxSuggestedRooms))
+			return
+		}
+	}
+
+	if err = describeSectionEvents(ctx, events); err != nil {
+		respondWithInternalError(c, err)
+		return
+	}
+	sortUnifiedEvents(events)
+
+	schedule := schema.RoomSchedule{
+		Building:   building,
+		Room:       room,
+		Week_start: dates[0],
+		Week_end:   dates[len(dates)-1],
+		Days:       make([]schema.RoomScheduleDay, len(days)),
+	}
+	for i, day := range days {
+		schedule.Days[i] = schema.RoomScheduleDay{Date: dates[i], Weekday: day.Weekday().String(), Events: []schema.UnifiedEvent{}}
+	}
+	for _, event := range events {
+		for i, day := range days {
+			if !event.Start.Before(day) && event.Start.Before(day.AddDate(0, 0, 1)) {
+				schedule.Days[i].Events = append(schedule.Days[i].Events, event)
+				break
+			}
+		}
+	}
+
+	respond(c, http.StatusOK, "success", schedule)
+}
+
+// weekDays returns the start of each day of the week of the given day, Monday first, in campus time
+func weekDays(day time.Time) []time.Time {
+	day = time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, campusLocation)
+	monday := day.AddDate(0, 0, -(int(day.Weekday())+6)%7)
+	days := make([]time.Time, 7)
+	for i := range days {
+		days[i] = monday.AddDate(0, 0, i)
+	}
+	return days
+}
+
+// findRoomSectionEvents finds the sections meeting in a room during the given days, one event per meeting on each day
+func findRoomSectionEvents(ctx context.Context, building string, room string, days []time.Time) ([]schema.UnifiedEvent, error) {
+	filter := roomSectionFilter(building, room, days)
+
+	var sections []schema.Section
+	cursor, err := sectionCollection.Find(ctx, filter, roomScheduleSectionProjection)
+	if err != nil {
+		return nil, err
+	}
+	if err = cursor.All(ctx, &sections); err != nil {
+		return nil, err
+	}
+	return roomSectionEvents(sections, building, room, days), nil
+}
+
+// roomSectionFilter matches the sections with a meeting in a room which may take place during the given days.
+// Locations are stored trimmed and uppercase, so they're matched exactly for an index on them to be used.
+// Meetings with missing, null or zero dates are kept as roomSectionEvents treats them as unknown.
+func roomSectionFilter(building string, room string, days []time.Time) bson.M {
+	unknownDate := bson.M{"$in": bson.A{nil, time.Time{}}}
+	return bson.M{"meetings": bson.M{"$elemMatch": bson.M{
+		"location.building": strings.ToUpper(strings.TrimSpace(building)),
+		"location.room":     strings.ToUpper(strings.TrimSpace(room)),
+		"$and": bson.A{
+			bson.M{"$or": bson.A{bson.M{"start_date": unknownDate}, bson.M{"start_date": bson.M{"$lte": dateOnly(days[len(days)-1])}}}},
+			bson.M{"$or": bson.A{bson.M{"end_date": unknownDate}, bson.M{"end_date": bson.M{"$gte": dateOnly(days[0])}}}},
+		},
+	}}}
+}
+
+// roomSectionEvents expands the meetings of sections in a room into an event on each of the days they take place
+func roomSectionEvents(sections []schema.Section, building string, room string, days []time.Time) []schema.UnifiedEvent {
+	events := make([]schema.UnifiedEvent, 0)
+	for _, section := range sections {
+		for _, interval := range meetingIntervals(section) {
+			if roomKey(interval.location.Building, interval.location.Room) != roomKey(building, room) {
+				continue
+			}
+			for _, day := range days {
+				if !interval.days.Has(day.Weekday()) ||
+					(!interval.startDate.IsZero() && dateOnly(day).Before(dateOnly(interval.startDate))) ||
+					(!interval.endDate.IsZero() && dateOnly(day).After(dateOnly(interval.endDate))) {
+					continue
+				}
+				start, end := atMinutes(day, interval.start), atMinutes(day, interval.end)
+				events = append(events, schema.UnifiedEvent{
+					Id:             section.Id.Hex(),
+					Source:         sourceCoursebook,
+					Start:          start,
+					End:            end,
+					Building:       strings.TrimSpace(interval.location.Building),
+					Room:           strings.TrimSpace(interval.location.Room),
+					Occupied_start: start,
+					Occupied_end:   end,
+				})
+			}
+		}
+	}
+	return events
+}
+
+// collectRoomEvents converts the events of a room on each day from one of the event sources into unified events
+func collectRoomEvents[T any](days []schema.MultiBuildingEvents[T], source string, building string, room string, convert func(day time.Time, event T) (schema.UnifiedEvent, bool)) []schema.UnifiedEvent {
+	var events []schema.UnifiedEvent
+	for _, dayEvents := range days {
+		day, err := parseEventDate(dayEvents.Date)
+		if err != nil {
+			continue
+		}
+		for _, event := range collectUnifiedEvents(dayEvents, source, day, convert) {
+			if roomKey(event.Building, event.Room) == roomKey(building, room) {
+				events = append(events, event)
+			}
+		}
+	}
+	return events
+}
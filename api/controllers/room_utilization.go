@@ -0,0 +1,308 @@
+package controllers
+
+import (
+	"cmp"
+	"context"
+	"math"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/UTDNebula/nebula-api/api/schema"
+)
+
+// Opening hours rooms are expected to be booked during when none are given
+const (
+	defaultOpenMinutes  = 7 * 60
+	defaultCloseMinutes = 22 * 60
+)
+
+// Maximum number of peak hours listed per building and for the whole campus
+const maxPeakHours = 5
+
+// Booked minutes of a room, or of a group of rooms weighted by their weights
+type utilizationTally struct {
+	hours  [7][24]float64 // booked minutes of each hour of the week, indexed by weekday then hour
+	open   float64        // booked minutes during opening hours
+	weight float64        // total weight of the rooms
+}
+
+// The dates and opening hours utilization is computed over
+type utilizationPeriod struct {
+	occurrences [7]int // number of times each weekday occurs
+	days        int
+	open        int // minutes since midnight
+	close       int // minutes since midnight
+}
+
+// @Id				roomsUtilization
+// @Router			/rooms/utilization [get]
+// @Tags			Events
+// @Description	"Returns how much of the time rooms are booked by sections, Astra and Mazevo events over a date range of up to 31 days, per building and room, along with heatmaps by weekday and hour, peak hours and idle rooms"
+// @Produce		json
+// @Param			from		query		string										true	"ISO date of the first day of the range"
+// @Param			to			query		string										true	"ISO date of the last day of the range, inclusive"
+// @Param			open		query		string										false	"Time rooms open each day (e.g. 8:00am), defaults to 7:00am"
+// @Param			close		query		string										false	"Time rooms close each day (e.g. 9:00pm), defaults to 10:00pm"
+// @Param			building	query		string										false	"Building abbreviation to limit the rooms to"
+// @Param			weighted	query		bool										false	"Weight the utilization of buildings and the campus by room capacity"
+// @Success		200			{object}	schema.APIResponse[schema.RoomsUtilization]	"Utilization of the rooms over the range"
+// @Failure		500			{object}	schema.APIResponse[string]					"A string describing the error"
+// @Failure		404			{object}	schema.APIResponse[string]					"A string describing the error"
+// @Failure		400			{object}	schema.APIResponse[string]					"A string describing the error"
+func RoomsUtilization(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 20*time.Second)
+	defer cancel()
+
+	dates, err := eventRangeQuery(c)
+	if err != nil {
+		return
+	}
+
+	period := utilizationPeriod{days: len(dates), open: defaultOpenMinutes, close: defaultCloseMinutes}
+	for _, date := range dates {
+		day, _ := parseEventDate(date) // dates of the range are always valid
+		period.occurrences[day.Weekday()]++
+	}
+	if value := c.Query("open"); value != "" {
+		if period.open, err = schema.ParseClockTime(value); err != nil {
+			respond(c, http.StatusBadRequest, "error", err.Error())
+			return
+		}
+	}
+	if value := c.Query("close"); value != "" {
+		if period.close, err = schema.ParseClockTime(value); err != nil {
+			respond(c, http.StatusBadRequest, "error", err.Error())
+			return
+		}
+	}
+	if period.close <= period.open {
+		respond(c, http.StatusBadRequest, "error", "close must be after open")
+		return
+	}
+	weighted := c.Query("weighted") == "true"
+
+	buildingRooms, err := findBuildingRooms(ctx, c, strings.TrimSpace(c.Query("building")))
+	if err != nil {
+		return
+	}
+
+	busy, err := findRangeBusyRanges(ctx, dates)
+	if err != nil {
+		respondWithInternalError(c, err)
+		return
+	}
+
+	utilization := schema.RoomsUtilization{
+		From:      dates[0],
+		To:        dates[len(dates)-1],
+		Open:      schema.FormatClockTime(period.open),
+		Close:     schema.FormatClockTime(period.close),
+		Weighted:  weighted,
+		Buildings: make([]schema.BuildingUtilization, 0, len(buildingRooms)),
+	}
+	var campus utilizationTally
+	for _, building := range buildingRooms {
+		buildingUtilization := schema.BuildingUtilization{
+			Building:   strings.TrimSpace(building.Building),
+			Idle_rooms: []string{},
+			Rooms:      make([]schema.RoomUtilization, 0, len(building.Rooms)),
+		}
+		var buildingTally utilizationTally
+		for _, room := range building.Rooms {
+			tally := roomUtilizationTally(busy[roomKey(building.Building, room.Room)], period)
+			weight := 1.0
+			if weighted {
+				weight = float64(room.Capacity)
+			}
+			buildingTally.add(tally, weight)
+			campus.add(tally, weight)
+
+			roomUtilization := schema.RoomUtilization{
+				Room:         strings.TrimSpace(room.Room),
+				Capacity:     room.Capacity,
+				Booked_hours: roundUtilization(tally.open / 60),
+				Utilization:  roundUtilization(period.utilization(tally)),
+			}
+			buildingUtilization.Rooms = append(buildingUtilization.Rooms, roomUtilization)
+			buildingUtilization.Booked_hours += tally.open / 60
+			if tally.open == 0 {
+				buildingUtilization.Idle_rooms = append(buildingUtilization.Idle_rooms, roomUtilization.Room)
+			}
+		}
+		slices.SortFunc(buildingUtilization.Rooms, func(a, b schema.RoomUtilization) int { return strings.Compare(a.Room, b.Room) })
+		slices.Sort(buildingUtilization.Idle_rooms)
+
+		utilization.Booked_hours += buildingUtilization.Booked_hours
+		buildingUtilization.Booked_hours = roundUtilization(buildingUtilization.Booked_hours)
+		buildingUtilization.Utilization = roundUtilization(period.utilization(buildingTally))
+		buildingUtilization.Heatmap = period.heatmap(buildingTally)
+		buildingUtilization.Peak_hours = period.peakHours(buildingTally)
+		utilization.Buildings = append(utilization.Buildings, buildingUtilization)
+	}
+	slices.SortFunc(utilization.Buildings, func(a, b schema.BuildingUtilization) int { return strings.Compare(a.Building, b.Building) })
+
+	utilization.Booked_hours = roundUtilization(utilization.Booked_hours)
+	utilization.Utilization = roundUtilization(period.utilization(campus))
+	utilization.Heatmap = period.heatmap(campus)
+	utilization.Peak_hours = period.peakHours(campus)
+
+	respond(c, http.StatusOK, "success", utilization)
+}
+
+// findRangeBusyRanges retrieves when each room is booked by sections, Astra or Mazevo events on the given dates, keyed by roomKey
+func findRangeBusyRanges(ctx context.Context, dates []string) (map[string][]schema.TimeRange, error) {
+	busy := make(map[string][]schema.TimeRange)
+
+	sections, err := findRangeEvents[schema.SectionWithTime](ctx, sectionEventSource.collection, dates)
+	if err != nil {
+		return nil, err
+	}
+	addBusyRanges(busy, sections, sectionEventSource.span)
+
+	astra, err := findRangeEvents[schema.AstraEvent](ctx, astraEventSource.collection, dates)
+	if err != nil {
+		return nil, err
+	}
+	addBusyRanges(busy, astra, astraOccupancySpan)
+
+	mazevo, err := findRangeEvents[schema.MazevoEvent](ctx, mazevoEventSource.collection, dates)
+	if err != nil {
+		return nil, err
+	}
+	addBusyRanges(busy, mazevo, mazevoOccupancySpan)
+
+	return busy, nil
+}
+
+// addBusyRanges adds the spans of the events of each day to the busy ranges of their rooms, cut off at the end of the day
+func addBusyRanges[T any](busy map[string][]schema.TimeRange, days []schema.MultiBuildingEvents[T], span eventSpan[T]) {
+	for _, events := range days {
+		day, err := parseEventDate(events.Date)
+		if err != nil {
+			continue
+		}
+		nextDay := day.AddDate(0, 0, 1)
+		for _, building := range events.Buildings {
+			for _, room := range building.Rooms {
+				key := roomKey(building.Building, room.Room)
+				for _, event := range room.Events {
+					start, end, ok := span(day, event)
+					if !ok {
+						continue
+					}
+					start, end = laterDate(start, day), earlierDate(end, nextDay)
+					if end.After(start) {
+						busy[key] = append(busy[key], schema.TimeRange{Start: start, End: end})
+					}
+				}
+			}
+		}
+	}
+}
+
+// roomUtilizationTally totals the minutes a room is booked, counting overlapping bookings once
+func roomUtilizationTally(busy []schema.TimeRange, period utilizationPeriod) utilizationTally {
+	tally := utilizationTally{weight: 1}
+
+	busy = slices.Clone(busy)
+	slices.SortFunc(busy, func(a, b schema.TimeRange) int { return a.Start.Compare(b.Start) })
+
+	var booked []schema.TimeRange
+	for _, span := range busy {
+		if last := len(booked) - 1; last >= 0 && !span.Start.After(booked[last].End) {
+			booked[last].End = laterDate(booked[last].End, span.End)
+			continue
+		}
+		booked = append(booked, span)
+	}
+
+	// Split each booking at the hour so every piece falls in a single hour of the week
+	for _, span := range booked {
+		for start := span.Start.In(campusLocation); start.Before(span.End); {
+			nextHour := time.Date(start.Year(), start.Month(), start.Day(), start.Hour()+1, 0, 0, 0, campusLocation)
+			end := earlierDate(nextHour, span.End)
+
+			minutes := end.Sub(start).Minutes()
+			tally.hours[start.Weekday()][start.Hour()] += minutes
+
+			startMinute := float64(start.Hour()*60+start.Minute()) + float64(start.Second())/60
+			openStart := max(startMinute, float64(period.open))
+			openEnd := min(startMinute+minutes, float64(period.close))
+			tally.open += max(openEnd-openStart, 0)
+
+			start = end
+		}
+	}
+	return tally
+}
+
+// add adds the booked minutes of a room to a group of rooms with the given weight
+func (tally *utilizationTally) add(room utilizationTally, weight float64) {
+	for day := range tally.hours {
+		for hour := range tally.hours[day] {
+			tally.hours[day][hour] += room.hours[day][hour] * weight
+		}
+	}
+	tally.open += room.open * weight
+	tally.weight += room.weight * weight
+}
+
+// utilization returns the share of opening hours booked
+func (period utilizationPeriod) utilization(tally utilizationTally) float64 {
+	available := tally.weight * float64(period.days*(period.close-period.open))
+	if available == 0 {
+		return 0
+	}
+	return tally.open / available
+}
+
+// hourUtilization returns the share of an hour of the week booked
+func (period utilizationPeriod) hourUtilization(tally utilizationTally, day time.Weekday, hour int) float64 {
+	available := tally.weight * float64(period.occurrences[day]*60)
+	if available == 0 {
+		return 0
+	}
+	return tally.hours[day][hour] / available
+}
+
+// heatmap returns the share of each hour booked by name of weekday, for the weekdays in the period
+func (period utilizationPeriod) heatmap(tally utilizationTally) map[string][]float64 {
+	heatmap := make(map[string][]float64)
+	for day := time.Sunday; day <= time.Saturday; day++ {
+		if period.occurrences[day] == 0 {
+			continue
+		}
+		hours := make([]float64, 24)
+		for hour := range hours {
+			hours[hour] = roundUtilization(period.hourUtilization(tally, day, hour))
+		}
+		heatmap[day.String()] = hours
+	}
+	return heatmap
+}
+
+// peakHours returns the most booked hours of the week, most booked first
+func (period utilizationPeriod) peakHours(tally utilizationTally) []schema.HourUtilization {
+	peaks := make([]schema.HourUtilization, 0)
+	for day := time.Sunday; day <= time.Saturday; day++ {
+		for hour := range 24 {
+			if utilization := period.hourUtilization(tally, day, hour); utilization > 0 {
+				peaks = append(peaks, schema.HourUtilization{Weekday: day.String(), Hour: hour, Utilization: roundUtilization(utilization)})
+			}
+		}
+	}
+	// Stable so ties stay in order of the week
+	slices.SortStableFunc(peaks, func(a, b schema.HourUtilization) int {
+		return cmp.Compare(b.Utilization, a.Utilization)
+	})
+	return peaks[:min(len(peaks), maxPeakHours)]
+}
+
+// roundUtilization rounds shares and hours to 4 decimal places
+func roundUtilization(value float64) float64 {
+	return math.Round(value*10000) / 10000
+}
@@ -1,8 +1,16 @@
 package controllers
 
 import (
+	"cmp"
 	"context"
+	"errors"
+	"fmt"
+	"math"
 	"net/http"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -47,3 +55,252 @@ func Rooms(c *gin.Context) {
 	// serialize RoomsResponse struct data into JSON format
 	respond(c, http.StatusOK, "success", buildingRooms)
 }
+
+// @Id				roomsAvailable
+// @Router			/rooms/available [get]
+// @Tags			Events
+// @Description	"Returns the rooms with no sections or events from CourseBook, Astra, Mazevo or the Comet Calendar during part of a time window on the given date, counting the setup and teardown of Mazevo events"
+// @Produce		json
+// @Param			date			query		string										true	"ISO date to find free rooms on (e.g. 2025-09-02)"
+// @Param			start			query		string										false	"Start of the time window (e.g. 2:00pm or 14:00), defaults to midnight"
+// @Param			end				query		string										false	"End of the time window (e.g. 4:00pm or 16:00), defaults to the end of the day"
+// @Param			duration		query		number										false	"Minimum free minutes, defaults to the whole window"
+// @Param			building		query		string										false	"Building abbreviation to find free rooms in"
+// @Param			min_capacity	query		number										false	"Minimum capacity of the rooms"
+// @Success		200				{object}	schema.APIResponse[[]schema.AvailableRoom]	"Free rooms along with their free stretches in the window"
+// @Failure		500				{object}	schema.APIResponse[string]					"A string describing the error"
+// @Failure		404				{object}	schema.APIResponse[string]					"A string describing the error"
+// @Failure		400				{object}	schema.APIResponse[string]					"A string describing the error"
+func RoomsAvailable(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	day, err := parseEventDate(c.Query("date"))
+	if err != nil {
+		respond(c, http.StatusBadRequest, "error", err.Error())
+		return
+	}
+
+	windowStart, windowEnd := day, day.AddDate(0, 0, 1)
+	window, err := eventWindowQuery(c, day)
+	if err != nil {
+		return
+	}
+	if window != nil {
+		windowStart, windowEnd = window.Start, window.End
+	}
+
+	duration := windowEnd.Sub(windowStart)
+	if value := c.Query("duration"); value != "" {
+		minutes, err := strconv.Atoi(value)
+		if err != nil || minutes <= 0 {
+			respond(c, http.StatusBadRequest, "error", "duration must be a positive number of minutes")
+			return
+		}
+		duration = time.Duration(minutes) * time.Minute
+	}
+
+	minCapacity := 0
+	if value := c.Query("min_capacity"); value != "" {
+		if minCapacity, err = strconv.Atoi(value); err != nil {
+			respond(c, http.StatusBadRequest, "error", "min_capacity must be a number")
+			return
+		}
+	}
+
+	buildingRooms, err := findBuildingRooms(ctx, c, strings.TrimSpace(c.Query("building")))
+	if err != nil {
+		return
+	}
+
+	events, err := findDayUnifiedEvents(ctx, day)
+	if err != nil {
+		respondWithInternalError(c, err)
+		return
+	}
+	busy := make(map[string][]schema.TimeRange)
+	for _, event := range events {
+		if !occupiesRoom(event) {
+			continue
+		}
+		key := roomKey(event.Building, event.Room)
+		busy[key] = append(busy[key], schema.TimeRange{Start: event.Occupied_start, End: event.Occupied_end})
+	}
+
+	available := make([]schema.AvailableRoom, 0)
+	for _, building := range buildingRooms {
+		for _, room := range building.Rooms {
+			if room.Capacity < minCapacity {
+				continue
+			}
+			free := freeRanges(busy[roomKey(building.Building, room.Room)], windowStart, windowEnd, duration)
+			if len(free) == 0 {
+				continue
+			}
+			available = append(available, schema.AvailableRoom{
+				Building: strings.TrimSpace(building.Building),
+				Room:     strings.TrimSpace(room.Room),
+				Capacity: room.Capacity,
+				Free:     free,
+			})
+		}
+	}
+	slices.SortFunc(available, func(a, b schema.AvailableRoom) int {
+		return strings.Compare(roomKey(a.Building, a.Room), roomKey(b.Building, b.Room))
+	})
+
+	respond(c, http.StatusOK, "success", available)
+}
+
+// Search radius around a point when none is given, and the largest allowed, in meters
+const (
+	defaultNearRadius = 500
+	maxNearRadius     = 10000
+)
+
+// Mean radius of the earth in meters
+const earthRadius = 6371000
+
+// @Id				roomsNear
+// @Router			/rooms/near [get]
+// @Tags			Events
+// @Description	"Returns the buildings within a radius of a point, nearest first, along with their rooms and capacities"
+// @Produce		json
+// @Param			lat		query		number										true	"Latitude of the point"
+// @Param			lng		query		number										true	"Longitude of the point"
+// @Param			radius	query		number										false	"Search radius in meters, defaults to 500 and can be at most 10000"
+// @Success		200		{object}	schema.APIResponse[[]schema.NearbyBuilding]	"Buildings within the radius ordered by distance"
+// @Failure		500		{object}	schema.APIResponse[string]					"A string describing the error"
+// @Failure		400		{object}	schema.APIResponse[string]					"A string describing the error"
+func RoomsNear(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	lat, err := strconv.ParseFloat(c.Query("lat"), 64)
+	if err != nil || lat < -90 || lat > 90 {
+		respond(c, http.StatusBadRequest, "error", "lat must be a latitude between -90 and 90")
+		return
+	}
+	lng, err := strconv.ParseFloat(c.Query("lng"), 64)
+	if err != nil || lng < -180 || lng > 180 {
+		respond(c, http.StatusBadRequest, "error", "lng must be a longitude between -180 and 180")
+		return
+	}
+	radius := float64(defaultNearRadius)
+	if value := c.Query("radius"); value != "" {
+		if radius, err = strconv.ParseFloat(value, 64); err != nil || radius <= 0 || radius > maxNearRadius {
+			respond(c, http.StatusBadRequest, "error", fmt.Sprintf("radius must be a positive number of meters up to %d", maxNearRadius))
+			return
+		}
+	}
+
+	buildingRooms, err := findBuildingRooms(ctx, c, "")
+	if err != nil {
+		return
+	}
+
+	nearby := make([]schema.NearbyBuilding, 0)
+	for _, building := range buildingRooms {
+		// Buildings without coordinates are stored at 0, 0
+		if building.Lat == 0 && building.Lng == 0 {
+			continue
+		}
+		distance := haversineDistance(lat, lng, building.Lat, building.Lng)
+		if distance > radius {
+			continue
+		}
+		nearby = append(nearby, schema.NearbyBuilding{
+			Building: strings.TrimSpace(building.Building),
+			Lat:      building.Lat,
+			Lng:      building.Lng,
+			Distance: math.Round(distance),
+			Rooms:    building.Rooms,
+		})
+	}
+	slices.SortStableFunc(nearby, func(a, b schema.NearbyBuilding) int { return cmp.Compare(a.Distance, b.Distance) })
+
+	respond(c, http.StatusOK, "success", nearby)
+}
+
+// haversineDistance returns the great-circle distance between two points in meters
+func haversineDistance(lat1 float64, lng1 float64, lat2 float64, lng2 float64) float64 {
+	toRadians := func(degrees float64) float64 { return degrees * math.Pi / 180 }
+	dLat := toRadians(lat2 - lat1)
+	dLng := toRadians(lng2 - lng1)
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(toRadians(lat1))*math.Cos(toRadians(lat2))*math.Sin(dLng/2)*math.Sin(dLng/2)
+	return 2 * earthRadius * math.Asin(math.Min(1, math.Sqrt(a)))
+}
+
+// findBuildingRooms retrieves the schedulable rooms of every building, or only of the given building if not empty.
+// Automatically responds with an error if the building can't be found.
+func findBuildingRooms(ctx context.Context, c *gin.Context, building string) ([]schema.BuildingRooms, error) {
+	filter := bson.M{}
+	if building != "" {
+		filter = buildingNameFilter(building, false)
+	}
+	buildingRooms, err := queryBuildingRooms(ctx, filter)
+	if err != nil {
+		respondWithInternalError(c, err)
+		return nil, err
+	}
+	if building != "" && len(buildingRooms) == 0 {
+		err = errors.New("Building not found")
+		respond(c, http.StatusNotFound, "error", err.Error())
+		return nil, err
+	}
+	return buildingRooms, nil
+}
+
+// queryBuildingRooms retrieves the schedulable rooms of the buildings matching the filter
+func queryBuildingRooms(ctx context.Context, filter bson.M) ([]schema.BuildingRooms, error) {
+	var buildingRooms []schema.BuildingRooms
+	cursor, err := buildingCollection.Find(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	if err = cursor.All(ctx, &buildingRooms); err != nil {
+		return nil, err
+	}
+	return buildingRooms, nil
+}
+
+// buildingNameFilter matches a building name case-insensitively ignoring surrounding spaces,
+// or every building whose name starts with it if prefix is true
+func buildingNameFilter(building string, prefix bool) bson.M {
+	pattern := `^\s*` + regexp.QuoteMeta(strings.TrimSpace(building))
+	if !prefix {
+		pattern += `\s*$`
+	}
+	return bson.M{"building": bson.D{{Key: "$regex", Value: pattern}, {Key: "$options", Value: "i"}}}
+}
+
+// freeRanges returns the stretches of the window not covered by any busy range which last at least the minimum duration
+func freeRanges(busy []schema.TimeRange, windowStart time.Time, windowEnd time.Time, minimum time.Duration) []schema.TimeRange {
+	busy = slices.Clone(busy)
+	slices.SortFunc(busy, func(a, b schema.TimeRange) int { return a.Start.Compare(b.Start) })
+
+	var free []schema.TimeRange
+	cursor := windowStart
+	for _, span := range busy {
+		if !span.End.After(cursor) {
+			continue
+		}
+		if span.Start.After(cursor) {
+			end := span.Start
+			if end.After(windowEnd) {
+				end = windowEnd
+			}
+			if end.Sub(cursor) >= minimum && end.After(cursor) {
+				free = append(free, schema.TimeRange{Start: cursor, End: end})
+			}
+		}
+		cursor = span.End
+		if !cursor.Before(windowEnd) {
+			return free
+		}
+	}
+	if windowEnd.Sub(cursor) >= minimum {
+		free = append(free, schema.TimeRange{Start: cursor, End: windowEnd})
+	}
+	return free
+}
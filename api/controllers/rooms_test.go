@@ -0,0 +1,204 @@
+package controllers
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/UTDNebula/nebula-api/api/schema"
+)
+
+func TestFreeRanges(t *testing.T) {
+	at := func(hour int, minute int) time.Time {
+		return time.Date(2025, 9, 2, hour, minute, 0, 0, campusLocation)
+	}
+	busy := []schema.TimeRange{
+		{Start: at(15, 0), End: at(15, 30)},
+		{Start: at(13, 0), End: at(14, 15)},
+		{Start: at(14, 0), End: at(14, 30)}, // overlaps the one before
+	}
+
+	testCases := map[string]struct {
+		Minimum  time.Duration
+		Expected []schema.TimeRange
+	}{
+		"AnyLength": {
+			Minimum:  time.Minute,
+			Expected: []schema.TimeRange{{Start: at(14, 30), End: at(15, 0)}, {Start: at(15, 30), End: at(16, 0)}},
+		},
+		"LongerThanGaps": {
+			Minimum: 45 * time.Minute,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			result := freeRanges(busy, at(14, 0), at(16, 0), tc.Minimum)
+			if len(result) != len(tc.Expected) {
+				t.Fatalf("Expected %v, got %v", tc.Expected, result)
+			}
+			for i := range result {
+				if !result[i].Start.Equal(tc.Expected[i].Start) || !result[i].End.Equal(tc.Expected[i].End) {
+					t.Errorf("Expected %v, got %v", tc.Expected, result)
+				}
+			}
+		})
+	}
+
+	if free := freeRanges(nil, at(14, 0), at(16, 0), 2*time.Hour); len(free) != 1 {
+		t.Errorf("Expected an empty room to be free for the whole window, got %v", free)
+	}
+}
+
+func TestRoomUtilization(t *testing.T) {
+	at := func(hour int, minute int) time.Time {
+		return time.Date(2025, 9, 2, hour, minute, 0, 0, campusLocation) // a Tuesday
+	}
+	period := utilizationPeriod{days: 1, open: 8 * 60, close: 18 * 60}
+	period.occurrences[time.Tuesday] = 1
+
+	small := roomUtilizationTally([]schema.TimeRange{
+		{Start: at(7, 30), End: at(9, 0)},   // half before opening
+		{Start: at(8, 30), End: at(9, 30)},  // overlaps the one before
+		{Start: at(13, 0), End: at(14, 15)}, // spans two hours
+	}, period)
+	if small.open != 165 {
+		t.Errorf("Expected 165 booked minutes during opening hours, got %v", small.open)
+	}
+	if got := period.hourUtilization(small, time.Tuesday, 7); got != 0.5 {
+		t.Errorf("Expected 7:00 to be half booked, got %v", got)
+	}
+	if got := period.hourUtilization(small, time.Tuesday, 14); got != 0.25 {
+		t.Errorf("Expected 14:00 to be a quarter booked, got %v", got)
+	}
+	if got := period.utilization(small); got != 0.275 {
+		t.Errorf("Expected 0.275 of opening hours booked, got %v", got)
+	}
+
+	idle := roomUtilizationTally(nil, period)
+	var unweighted, weighted utilizationTally
+	unweighted.add(small, 1)
+	unweighted.add(idle, 1)
+	weighted.add(small, 30)
+	weighted.add(idle, 10)
+	if got := period.utilization(unweighted); got != 0.1375 {
+		t.Errorf("Expected 0.1375 of opening hours booked, got %v", got)
+	}
+	if got := period.utilization(weighted); got != 0.20625 {
+		t.Errorf("Expected capacity weighted utilization of 0.20625, got %v", got)
+	}
+
+	peaks := period.peakHours(small)
+	if len(peaks) != 5 || peaks[0].Hour != 8 || peaks[0].Utilization != 1 || peaks[0].Weekday != "Tuesday" {
+		t.Errorf("Expected 8:00 on Tuesday to be the peak hour, got %v", peaks)
+	}
+	if heatmap := period.heatmap(small); len(heatmap) != 1 || len(heatmap["Tuesday"]) != 24 {
+		t.Errorf("Expected a heatmap of Tuesday only, got %v", heatmap)
+	}
+}
+
+func TestHaversineDistance(t *testing.T) {
+	testCases := map[string]struct {
+		Lat1, Lng1, Lat2, Lng2 float64
+		Expected               float64 // meters
+	}{
+		"SamePoint":     {Lat1: 32.9857, Lng1: -96.7502, Lat2: 32.9857, Lng2: -96.7502, Expected: 0},
+		"OneDegreeLat":  {Lat1: 32, Lng1: -96, Lat2: 33, Lng2: -96, Expected: 111195},
+		"AcrossCampus":  {Lat1: 32.98612, Lng1: -96.75065, Lat2: 32.98453, Lng2: -96.74916, Expected: 225},
+		"QuarterGlobe":  {Lat1: 0, Lng1: 0, Lat2: 0, Lng2: 90, Expected: 10007543},
+		"OppositePoles": {Lat1: 90, Lng1: 0, Lat2: -90, Lng2: 0, Expected: 20015087},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			if result := haversineDistance(tc.Lat1, tc.Lng1, tc.Lat2, tc.Lng2); math.Abs(result-tc.Expected) > 1 {
+				t.Errorf("Expected %v meters, got %v", tc.Expected, result)
+			}
+		})
+	}
+}
+
+func TestRoomSectionEvents(t *testing.T) {
+	days := weekDays(time.Date(2025, time.September, 3, 15, 0, 0, 0, campusLocation))
+	if days[0].Weekday() != time.Monday || days[0].Day() != 1 || days[6].Day() != 7 {
+		t.Fatalf("Expected the week of Monday, September 1st, got %v to %v", days[0], days[6])
+	}
+
+	section := schema.Section{Id: primitive.NewObjectID(), Meetings: []schema.Meeting{
+		{
+			Start_date:   time.Date(2025, time.August, 25, 0, 0, 0, 0, time.UTC),
+			End_date:     time.Date(2025, time.September, 3, 0, 0, 0, 0, time.UTC),
+			Meeting_days: []string{"Monday", "Wednesday", "Friday"},
+			Start_time:   "10:00am",
+			End_time:     "10:50am",
+			Location:     schema.Location{Building: "ECSS", Room: "2.410"},
+		},
+		{
+			Meeting_days: []string{"Tuesday"},
+			Start_time:   "1:00pm",
+			End_time:     "2:15pm",
+			Location:     schema.Location{Building: "JSOM", Room: "1.118"},
+		},
+	}}
+
+	// The Friday meeting is after the end date and the Tuesday one is in another room
+	events := roomSectionEvents([]schema.Section{section}, "ecss", " 2.410 ", days)
+	if len(events) != 2 {
+		t.Fatalf("Expected meetings on Monday and Wednesday, got %v", events)
+	}
+	if expected := time.Date(2025, time.September, 3, 10, 0, 0, 0, campusLocation); !events[1].Start.Equal(expected) {
+		t.Errorf("Expected the second meeting to start at %v, got %v", expected, events[1].Start)
+	}
+	if events[0].Id != section.Id.Hex() || events[0].Source != sourceCoursebook {
+		t.Errorf("Expected coursebook events of the section, got %v", events[0])
+	}
+}
+
+func TestMapBuildings(t *testing.T) {
+	name, acronym, lat, lng := "Engineering and Computer Science South", "ecss", 1.0, 2.0
+	parking, parkingLat, parkingLng := "PS3", 5.0, 6.0
+	buildings := mapBuildings(
+		[]schema.BuildingRooms{
+			{Building: "ECSS", Lat: 3, Lng: 4},
+			{Building: " JSOM "},
+		},
+		[]schema.MapBuilding{
+			{Name: &name, Acronym: &acronym, Lat: &lat, Lng: &lng},
+			{Acronym: &parking, Lat: &parkingLat, Lng: &parkingLng},
+		},
+	)
+
+	if len(buildings) != 3 {
+		t.Fatalf("Expected ECSS, JSOM and PS3, got %d buildings", len(buildings))
+	}
+	if ecss := buildings[0]; *ecss.Acronym != "ECSS" || ecss.Name == nil || *ecss.Name != name || *ecss.Lat != 3 || *ecss.Lng != 4 {
+		t.Errorf("Expected ECSS named from the map and located from its rooms, got %+v", ecss)
+	}
+	if jsom := buildings[1]; *jsom.Acronym != "JSOM" || jsom.Name != nil || jsom.Lat != nil {
+		t.Errorf("Expected JSOM without a name or location, got %+v", jsom)
+	}
+	if ps3 := buildings[2]; *ps3.Acronym != "PS3" || *ps3.Lat != 5 {
+		t.Errorf("Expected PS3 from the map only, got %+v", ps3)
+	}
+}
+
+func TestRoomSectionFilter(t *testing.T) {
+	days := weekDays(time.Date(2025, time.September, 3, 15, 0, 0, 0, campusLocation))
+	meeting := roomSectionFilter(" ecss", "2.410a ", days)["meetings"].(bson.M)["$elemMatch"].(bson.M)
+
+	if meeting["location.building"] != "ECSS" || meeting["location.room"] != "2.410A" {
+		t.Errorf("Expected the location to be matched exactly once normalized, got %v and %v", meeting["location.building"], meeting["location.room"])
+	}
+
+	// Either date may be unknown, like roomSectionEvents allows
+	for i, field := range []string{"start_date", "end_date"} {
+		dates := meeting["$and"].(bson.A)[i].(bson.M)["$or"].(bson.A)
+		unknown := dates[0].(bson.M)[field].(bson.M)["$in"].(bson.A)
+		if len(unknown) != 2 || unknown[0] != nil || !unknown[1].(time.Time).IsZero() {
+			t.Errorf("Expected missing, null and zero %s values to match, got %v", field, dates)
+		}
+	}
+}
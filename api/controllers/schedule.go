@@ -0,0 +1,220 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/UTDNebula/nebula-api/api/schema"
+
+	"github.com/gin-gonic/gin"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Maximum number of sections a single schedule request can include
+const maxScheduleSections = 30
+
+// A single meeting of a section parsed into comparable days, times and dates
+type meetingInterval struct {
+	section   primitive.ObjectID
+	days      schema.Weekdays
+	start     int       // minutes since midnight
+	end       int       // minutes since midnight
+	startDate time.Time // zero if the meeting has no start date
+	endDate   time.Time // zero if the meeting has no end date
+	location  schema.Location
+}
+
+// @Id				scheduleConflicts
+// @Router			/schedule/conflicts [post]
+// @Tags			Schedule
+// @Description	"Returns every pair of the given sections whose meetings overlap, with the days, times and dates of the overlap"
+// @Accept			json
+// @Produce		json
+// @Param			body	body		schema.ScheduleSectionsBody						true	"IDs of the sections to check"
+// @Success		200		{object}	schema.APIResponse[[]schema.ScheduleConflict]	"All overlapping meetings"
+// @Failure		500		{object}	schema.APIResponse[string]						"A string describing the error"
+// @Failure		404		{object}	schema.APIResponse[string]						"A string describing the error"
+// @Failure		400		{object}	schema.APIResponse[string]						"A string describing the error"
+func ScheduleConflicts(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	var body schema.ScheduleSectionsBody
+	if err := c.ShouldBindJSON(&body); err != nil {
+		respond(c, http.StatusBadRequest, "Invalid request body", err.Error())
+		return
+	}
+
+	sections, err := findScheduleSections(ctx, c, body.Sections)
+	if err != nil {
+		return
+	}
+
+	respond(c, http.StatusOK, "success", scheduleConflicts(sections))
+}
+
+// findScheduleSections retrieves the sections with the given IDs in the order given.
+// Automatically responds with an error if the IDs are invalid or any section can't be found.
+func findScheduleSections(ctx context.Context, c *gin.Context, ids []primitive.ObjectID) ([]schema.Section, error) {
+	var found []schema.Section
+
+	// Drop duplicate IDs, a section can't conflict with itself
+	unique := make([]primitive.ObjectID, 0, len(ids))
+	for _, id := range ids {
+		if !slices.Contains(unique, id) {
+			unique = append(unique, id)
+		}
+	}
+	ids = unique
+	if len(ids) == 0 || len(ids) > maxScheduleSections {
+		err := fmt.Errorf("between 1 and %d sections are required", maxScheduleSections)
+		respond(c, http.StatusBadRequest, "Invalid request body", err.Error())
+		return nil, err
+	}
+
+	cursor, err := sectionCollection.Find(ctx, bson.M{"_id": bson.M{"$in": ids}})
+	if err != nil {
+		respondWithInternalError(c, err)
+		return nil, err
+	}
+	if err = cursor.All(ctx, &found); err != nil {
+		respondWithInternalError(c, err)
+		return nil, err
+	}
+
+	sections := make([]schema.Section, 0, len(ids))
+	var missing []string
+	for _, id := range ids {
+		index := slices.IndexFunc(found, func(section schema.Section) bool { return section.Id == id })
+		if index < 0 {
+			missing = append(missing, id.Hex())
+			continue
+		}
+		sections = append(sections, found[index])
+	}
+	if len(missing) > 0 {
+		err = fmt.Errorf("no sections with IDs %s", strings.Join(missing, ", "))
+		respond(c, http.StatusNotFound, "error", err.Error())
+		return nil, err
+	}
+
+	return sections, nil
+}
+
+// scheduleConflicts returns every overlap between meetings of different sections
+func scheduleConflicts(sections []schema.Section) []schema.ScheduleConflict {
+	conflicts := make([]schema.ScheduleConflict, 0)
+
+	intervals := make([][]meetingInterval, len(sections))
+	for i, section := range sections {
+		intervals[i] = meetingIntervals(section)
+	}
+
+	for i := range intervals {
+		for j := i + 1; j < len(intervals); j++ {
+			for _, a := range intervals[i] {
+				for _, b := range intervals[j] {
+					if conflict, ok := meetingConflict(a, b); ok {
+						conflicts = append(conflicts, conflict)
+					}
+				}
+			}
+		}
+	}
+	return conflicts
+}
+
+// meetingIntervals parses the meetings of a section, skipping meetings without days or parseable times
+func meetingIntervals(section schema.Section) []meetingInterval {
+	var intervals []meetingInterval
+	for _, meeting := range section.Meetings {
+		// Meetings decoded from the database already have their times computed, but not ones built in code
+		meeting.ComputeTimes()
+		if meeting.Start_minutes == nil || meeting.End_minutes == nil || *meeting.End_minutes <= *meeting.Start_minutes || meeting.Days == 0 {
+			continue
+		}
+
+		intervals = append(intervals, meetingInterval{
+			section:   section.Id,
+			days:      meeting.Days,
+			start:     *meeting.Start_minutes,
+			end:       *meeting.End_minutes,
+			startDate: meeting.Start_date,
+			endDate:   meeting.End_date,
+			location:  meeting.Location,
+		})
+	}
+	return intervals
+}
+
+// meetingConflict determines whether two meetings overlap on at least one day both of them actually take place.
+// Meetings in disjoint date ranges, such as the two halves of a half-term, never conflict.
+func meetingConflict(a meetingInterval, b meetingInterval) (schema.ScheduleConflict, bool) {
+	if a.start >= b.end || b.start >= a.end {
+		return schema.ScheduleConflict{}, false
+	}
+
+	startDate := laterDate(a.startDate, b.startDate)
+	endDate := earlierDate(a.endDate, b.endDate)
+	if !startDate.IsZero() && !endDate.IsZero() && dateOnly(startDate).After(dateOnly(endDate)) {
+		return schema.ScheduleConflict{}, false
+	}
+
+	shared := a.days & b.days & weekdaysBetween(startDate, endDate)
+	if shared == 0 {
+		return schema.ScheduleConflict{}, false
+	}
+	var days []string
+	for _, day := range shared.Days() {
+		days = append(days, day.String())
+	}
+
+	return schema.ScheduleConflict{
+		Sections:   [2]primitive.ObjectID{a.section, b.section},
+		Days:       days,
+		Start_time: schema.FormatClockTime(max(a.start, b.start)),
+		End_time:   schema.FormatClockTime(min(a.end, b.end)),
+		Start_date: startDate,
+		End_date:   endDate,
+	}, true
+}
+
+// weekdaysBetween returns the weekdays occurring between two dates, inclusive.
+// Every weekday occurs if either date is unknown or the range spans a full week.
+func weekdaysBetween(from time.Time, to time.Time) schema.Weekdays {
+	if from.IsZero() || to.IsZero() || dateOnly(to).Sub(dateOnly(from)) >= 6*24*time.Hour {
+		return schema.NewWeekdays(time.Sunday, time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday, time.Saturday)
+	}
+	var occurring schema.Weekdays
+	for day := dateOnly(from); !day.After(dateOnly(to)); day = day.AddDate(0, 0, 1) {
+		occurring |= schema.NewWeekdays(day.Weekday())
+	}
+	return occurring
+}
+
+// dateOnly drops the time of day from t
+func dateOnly(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// laterDate returns the later of two dates, ignoring unknown (zero) dates
+func laterDate(a time.Time, b time.Time) time.Time {
+	if a.IsZero() || b.After(a) {
+		return b
+	}
+	return a
+}
+
+// earlierDate returns the earlier of two dates, ignoring unknown (zero) dates
+func earlierDate(a time.Time, b time.Time) time.Time {
+	if a.IsZero() || (!b.IsZero() && b.Before(a)) {
+		return b
+	}
+	return a
+}
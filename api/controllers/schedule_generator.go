@@ -0,0 +1,498 @@
+package controllers
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"net/http"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/UTDNebula/nebula-api/api/schema"
+
+	"github.com/gin-gonic/gin"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Limits keeping the search for schedules bounded
+const (
+	maxGenerateCourses      = 10
+	defaultGeneratedResults = 10
+	maxGeneratedResults     = 50
+	maxScheduleCandidates   = 2000   // complete schedules kept for ranking
+	maxScheduleSearchSteps  = 200000 // sections tried during the search
+)
+
+// Matches course codes such as "CS 3345" or "cs3345"
+var courseCodeRegex = regexp.MustCompile(`^([A-Za-z]{2,4})\s*([0-9A-Za-z]{4})$`)
+
+// Fields of a section needed to generate schedules
+var generatorSectionProjection = options.Find().SetProjection(bson.M{
+	"section_number":   1,
+	"course_reference": 1,
+	"professors":       1,
+	"instruction_mode": 1,
+	"meetings":         1,
+})
+
+// A section of a requested course along with its parsed meetings
+type scheduleCandidate struct {
+	course    int // index of the course in the request
+	section   schema.Section
+	intervals []meetingInterval
+}
+
+// Parsed schedule constraints, times are in minutes since midnight and -1 when unset
+type scheduleConstraints struct {
+	earliestStart   int
+	latestEnd       int
+	daysOff         []time.Weekday
+	modalities      []string
+	professors      map[int]map[primitive.ObjectID]bool // professors of the courses they're given for, by index of the course
+	avoidProfessors map[primitive.ObjectID]bool
+}
+
+// @Id				scheduleGenerate
+// @Router			/schedule/generate [post]
+// @Tags			Schedule
+// @Description	"Returns conflict-free schedules with one section of each given course in an academic session. Sections must satisfy the required constraints and schedules are ranked by how well they satisfy the preferred ones."
+// @Accept			json
+// @Produce		json
+// @Param			body	body		schema.ScheduleGenerateBody						true	"The courses, academic session and constraints of the schedules"
+// @Success		200		{object}	schema.APIResponse[schema.GeneratedSchedules]	"The best scoring schedules"
+// @Failure		500		{object}	schema.APIResponse[string]						"A string describing the error"
+// @Failure		404		{object}	schema.APIResponse[string]						"A string describing the error"
+// @Failure		400		{object}	schema.APIResponse[string]						"A string describing the error"
+func ScheduleGenerate(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	var body schema.ScheduleGenerateBody
+	if err := c.ShouldBindJSON(&body); err != nil {
+		respond(c, http.StatusBadRequest, "Invalid request body", err.Error())
+		return
+	}
+
+	body.Academic_session = strings.TrimSpace(body.Academic_session)
+	if body.Academic_session == "" {
+		respond(c, http.StatusBadRequest, "Invalid request body", "academic_session is required")
+		return
+	}
+
+	codes, err := parseCourseCodes(body.Courses)
+	if err != nil {
+		respond(c, http.StatusBadRequest, "Invalid request body", err.Error())
+		return
+	}
+
+	required, err := parseScheduleConstraints(body.Require, codes)
+	if err != nil {
+		respond(c, http.StatusBadRequest, "Invalid require constraints", err.Error())
+		return
+	}
+	preferred, err := parseScheduleConstraints(body.Prefer, codes)
+	if err != nil {
+		respond(c, http.StatusBadRequest, "Invalid prefer constraints", err.Error())
+		return
+	}
+
+	limit := body.Limit
+	if limit <= 0 {
+		limit = defaultGeneratedResults
+	}
+	limit = min(limit, maxGeneratedResults)
+
+	candidates, err := findScheduleCandidates(ctx, c, codes, body.Academic_session)
+	if err != nil {
+		return
+	}
+
+	// Apply the required constraints to every section on its own, then rank the rest by preference so the best are searched first
+	unschedulable := make([]string, 0)
+	for i := range candidates {
+		candidates[i] = slices.DeleteFunc(candidates[i], func(candidate scheduleCandidate) bool {
+			return !required.allows(candidate)
+		})
+		slices.SortStableFunc(candidates[i], func(a, b scheduleCandidate) int {
+			return cmp.Compare(preferred.score([]scheduleCandidate{b}), preferred.score([]scheduleCandidate{a}))
+		})
+		if len(candidates[i]) == 0 {
+			unschedulable = append(unschedulable, codes[i])
+		}
+	}
+
+	result := schema.GeneratedSchedules{Schedules: make([]schema.GeneratedSchedule, 0), Unschedulable: unschedulable}
+	if len(unschedulable) > 0 {
+		respond(c, http.StatusOK, "success", result)
+		return
+	}
+
+	schedules, truncated := searchSchedules(candidates)
+	result.Found = len(schedules)
+	result.Truncated = truncated
+	for _, schedule := range rankSchedules(schedules, preferred, limit) {
+		result.Schedules = append(result.Schedules, generatedSchedule(schedule, codes, preferred))
+	}
+
+	respond(c, http.StatusOK, "success", result)
+}
+
+// parseCourseCodes normalizes the requested course codes, dropping duplicates
+func parseCourseCodes(courses []string) ([]string, error) {
+	var codes []string
+	for _, course := range courses {
+		match := courseCodeRegex.FindStringSubmatch(strings.TrimSpace(course))
+		if match == nil {
+			return nil, fmt.Errorf("invalid course code '%s', expected a prefix and number such as 'CS 3345'", course)
+		}
+		if code := courseCode(match[1], match[2]); !slices.Contains(codes, code) {
+			codes = append(codes, code)
+		}
+	}
+	if len(codes) == 0 || len(codes) > maxGenerateCourses {
+		return nil, fmt.Errorf("between 1 and %d courses are required", maxGenerateCourses)
+	}
+	return codes, nil
+}
+
+// parseScheduleConstraints validates the constraints of a request for the given normalized course codes
+func parseScheduleConstraints(constraints schema.ScheduleConstraints, codes []string) (scheduleConstraints, error) {
+	parsed := scheduleConstraints{
+		earliestStart:   -1,
+		latestEnd:       -1,
+		professors:      make(map[int]map[primitive.ObjectID]bool),
+		avoidProfessors: make(map[primitive.ObjectID]bool),
+	}
+
+	var err error
+	if constraints.Earliest_start != "" {
+		if parsed.earliestStart, err = schema.ParseClockTime(constraints.Earliest_start); err != nil {
+			return parsed, err
+		}
+	}
+	if constraints.Latest_end != "" {
+		if parsed.latestEnd, err = schema.ParseClockTime(constraints.Latest_end); err != nil {
+			return parsed, err
+		}
+	}
+	for _, day := range constraints.Days_off {
+		weekday, ok := schema.ParseWeekday(day)
+		if !ok {
+			return parsed, fmt.Errorf("invalid day '%s'", day)
+		}
+		if !slices.Contains(parsed.daysOff, weekday) {
+			parsed.daysOff = append(parsed.daysOff, weekday)
+		}
+	}
+	for _, modality := range constraints.Modalities {
+		parsed.modalities = append(parsed.modalities, normalizeModality(modality))
+	}
+	for course, professors := range constraints.Professors {
+		match := courseCodeRegex.FindStringSubmatch(strings.TrimSpace(course))
+		index := -1
+		if match != nil {
+			index = slices.Index(codes, courseCode(match[1], match[2]))
+		}
+		if index < 0 {
+			return parsed, fmt.Errorf("professors are given for '%s', which isn't one of the requested courses", course)
+		}
+		if len(professors) == 0 {
+			continue
+		}
+		if parsed.professors[index] == nil {
+			parsed.professors[index] = make(map[primitive.ObjectID]bool)
+		}
+		for _, professor := range professors {
+			parsed.professors[index][professor] = true
+		}
+	}
+	for _, professor := range constraints.Avoid_professors {
+		parsed.avoidProfessors[professor] = true
+	}
+	return parsed, nil
+}
+
+// findScheduleCandidates retrieves the sections of every course in the academic session, indexed like the codes.
+// Automatically responds with an error if any course doesn't exist.
+func findScheduleCandidates(ctx context.Context, c *gin.Context, codes []string, session string) ([][]scheduleCandidate, error) {
+	codeFilters := make(bson.A, 0, len(codes))
+	for _, code := range codes {
+		prefix, number, _ := strings.Cut(code, " ")
+		codeFilters = append(codeFilters, bson.M{"subject_prefix": prefix, "course_number": number})
+	}
+
+	// Every catalog year of a course has its own ID
+	courses, err := findEquivalentCandidates(ctx, bson.M{"$or": codeFilters})
+	if err != nil {
+		respondWithInternalError(c, err)
+		return nil, err
+	}
+	courseIndexes := make(map[primitive.ObjectID]int, len(courses))
+	courseIDs := make([]primitive.ObjectID, 0, len(courses))
+	for _, course := range courses {
+		courseIndexes[course.Id] = slices.Index(codes, courseCode(course.Subject_prefix, course.Course_number))
+		courseIDs = append(courseIDs, course.Id)
+	}
+
+	var missing []string
+	for i, code := range codes {
+		if !slices.ContainsFunc(courses, func(course schema.EquivalentCourse) bool { return courseIndexes[course.Id] == i }) {
+			missing = append(missing, code)
+		}
+	}
+	if len(missing) > 0 {
+		err = fmt.Errorf("no courses %s", strings.Join(missing, ", "))
+		respond(c, http.StatusNotFound, "error", err.Error())
+		return nil, err
+	}
+
+	var sections []schema.Section
+	cursor, err := sectionCollection.Find(ctx, bson.M{
+		"course_reference":      bson.M{"$in": courseIDs},
+		"academic_session.name": session,
+	}, generatorSectionProjection)
+	if err != nil {
+		respondWithInternalError(c, err)
+		return nil, err
+	}
+	if err = cursor.All(ctx, &sections); err != nil {
+		respondWithInternalError(c, err)
+		return nil, err
+	}
+
+	candidates := make([][]scheduleCandidate, len(codes))
+	for _, section := range sections {
+		course := courseIndexes[section.Course_reference]
+		candidates[course] = append(candidates[course], scheduleCandidate{
+			course:    course,
+			section:   section,
+			intervals: meetingIntervals(section),
+		})
+	}
+	return candidates, nil
+}
+
+// searchSchedules finds conflict-free combinations of one candidate per course with a backtracking search.
+// The search stops early, reporting it was truncated, once too many schedules are found or sections tried.
+func searchSchedules(candidates [][]scheduleCandidate) ([][]scheduleCandidate, bool) {
+	var schedules [][]scheduleCandidate
+
+	// Search the courses with the fewest candidates first to prune early
+	order := make([][]scheduleCandidate, len(candidates))
+	copy(order, candidates)
+	slices.SortStableFunc(order, func(a, b []scheduleCandidate) int { return cmp.Compare(len(a), len(b)) })
+
+	steps := 0
+	chosen := make([]scheduleCandidate, 0, len(order))
+	var search func(depth int) bool
+	search = func(depth int) bool {
+		if depth == len(order) {
+			schedules = append(schedules, slices.Clone(chosen))
+			return len(schedules) < maxScheduleCandidates
+		}
+		for _, candidate := range order[depth] {
+			if steps++; steps > maxScheduleSearchSteps {
+				return false
+			}
+			if slices.ContainsFunc(chosen, func(other scheduleCandidate) bool { return candidatesConflict(candidate, other) }) {
+				continue
+			}
+			chosen = append(chosen, candidate)
+			complete := search(depth + 1)
+			chosen = chosen[:len(chosen)-1]
+			if !complete {
+				return false
+			}
+		}
+		return true
+	}
+	truncated := !search(0)
+
+	// Put the sections back in the order of the requested courses
+	for _, schedule := range schedules {
+		slices.SortFunc(schedule, func(a, b scheduleCandidate) int { return cmp.Compare(a.course, b.course) })
+	}
+	return schedules, truncated
+}
+
+// candidatesConflict determines whether any meetings of two sections overlap
+func candidatesConflict(a scheduleCandidate, b scheduleCandidate) bool {
+	for _, aInterval := range a.intervals {
+		for _, bInterval := range b.intervals {
+			if _, ok := meetingConflict(aInterval, bInterval); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// rankSchedules returns the best scoring schedules, breaking ties on the fewest days with classes
+func rankSchedules(schedules [][]scheduleCandidate, preferred scheduleConstraints, limit int) [][]scheduleCandidate {
+	type scoredSchedule struct {
+		schedule []scheduleCandidate
+		score    float64
+		days     int
+	}
+	scored := make([]scoredSchedule, 0, len(schedules))
+	for _, schedule := range schedules {
+		scored = append(scored, scoredSchedule{schedule, preferred.score(schedule), len(scheduleDays(schedule).Days())})
+	}
+
+	slices.SortStableFunc(scored, func(a, b scoredSchedule) int {
+		if byScore := cmp.Compare(b.score, a.score); byScore != 0 {
+			return byScore
+		}
+		return cmp.Compare(a.days, b.days)
+	})
+
+	ranked := make([][]scheduleCandidate, 0, min(limit, len(scored)))
+	for _, schedule := range scored[:min(limit, len(scored))] {
+		ranked = append(ranked, schedule.schedule)
+	}
+	return ranked
+}
+
+// generatedSchedule converts a schedule into its response form
+func generatedSchedule(schedule []scheduleCandidate, codes []string, preferred scheduleConstraints) schema.GeneratedSchedule {
+	sections := make([]schema.ScheduledSection, 0, len(schedule))
+	for _, candidate := range schedule {
+		sections = append(sections, schema.ScheduledSection{
+			Course:           codes[candidate.course],
+			Id:               candidate.section.Id,
+			Section_number:   candidate.section.Section_number,
+			Professors:       candidate.section.Professors,
+			Instruction_mode: candidate.section.Instruction_mode,
+			Meetings:         candidate.section.Meetings,
+		})
+	}
+	return schema.GeneratedSchedule{Score: preferred.score(schedule), Sections: sections}
+}
+
+// scheduleDays returns the weekdays on which any section of a schedule meets
+func scheduleDays(schedule []scheduleCandidate) schema.Weekdays {
+	var days schema.Weekdays
+	for _, candidate := range schedule {
+		for _, interval := range candidate.intervals {
+			days |= interval.days
+		}
+	}
+	return days
+}
+
+// allows determines whether a section fully satisfies the constraints
+func (constraints scheduleConstraints) allows(candidate scheduleCandidate) bool {
+	return constraints.score([]scheduleCandidate{candidate}) == 1
+}
+
+// score rates how well a schedule satisfies the constraints from 0 to 1, averaging every constraint which is set.
+// A schedule always fully satisfies constraints which aren't set.
+func (constraints scheduleConstraints) score(schedule []scheduleCandidate) float64 {
+	var scores []float64
+
+	// Share of sections satisfying a section-level constraint
+	share := func(satisfies func(candidate scheduleCandidate) float64) float64 {
+		total := 0.0
+		for _, candidate := range schedule {
+			total += satisfies(candidate)
+		}
+		return total / float64(len(schedule))
+	}
+
+	if constraints.earliestStart >= 0 || constraints.latestEnd >= 0 {
+		scores = append(scores, share(func(candidate scheduleCandidate) float64 {
+			if len(candidate.intervals) == 0 {
+				return 1
+			}
+			within := 0
+			for _, interval := range candidate.intervals {
+				if (constraints.earliestStart < 0 || interval.start >= constraints.earliestStart) &&
+					(constraints.latestEnd < 0 || interval.end <= constraints.latestEnd) {
+					within++
+				}
+			}
+			return float64(within) / float64(len(candidate.intervals))
+		}))
+	}
+	if len(constraints.daysOff) > 0 {
+		days := scheduleDays(schedule)
+		free := 0
+		for _, day := range constraints.daysOff {
+			if !days.Has(day) {
+				free++
+			}
+		}
+		scores = append(scores, float64(free)/float64(len(constraints.daysOff)))
+	}
+	if len(constraints.modalities) > 0 {
+		scores = append(scores, share(func(candidate scheduleCandidate) float64 {
+			return boolScore(slices.ContainsFunc(sectionModalities(candidate.section), func(modality string) bool {
+				return slices.Contains(constraints.modalities, modality)
+			}))
+		}))
+	}
+	if len(constraints.professors) > 0 {
+		// Only the sections of courses with professors given are rated
+		total, rated := 0.0, 0
+		for _, candidate := range schedule {
+			if professors, ok := constraints.professors[candidate.course]; ok {
+				rated++
+				total += boolScore(slices.ContainsFunc(candidate.section.Professors, func(professor primitive.ObjectID) bool {
+					return professors[professor]
+				}))
+			}
+		}
+		if rated > 0 {
+			scores = append(scores, total/float64(rated))
+		}
+	}
+	if len(constraints.avoidProfessors) > 0 {
+		scores = append(scores, share(func(candidate scheduleCandidate) float64 {
+			return boolScore(!slices.ContainsFunc(candidate.section.Professors, func(professor primitive.ObjectID) bool {
+				return constraints.avoidProfessors[professor]
+			}))
+		}))
+	}
+
+	if len(scores) == 0 || len(schedule) == 0 {
+		return 1
+	}
+	total := 0.0
+	for _, score := range scores {
+		total += score
+	}
+	return total / float64(len(scores))
+}
+
+// sectionModalities returns the normalized modalities of a section, from its instruction mode or else its meetings
+func sectionModalities(section schema.Section) []string {
+	if section.Instruction_mode != "" {
+		return []string{normalizeModality(section.Instruction_mode)}
+	}
+	var modalities []string
+	for _, meeting := range section.Meetings {
+		if meeting.Modality != "" {
+			modalities = append(modalities, normalizeModality(meeting.Modality))
+		}
+	}
+	return modalities
+}
+
+// normalizeModality makes modalities comparable, e.g. "Face-to-Face" and "face to face"
+func normalizeModality(modality string) string {
+	return strings.Join(strings.FieldsFunc(strings.ToLower(modality), func(r rune) bool {
+		return r == ' ' || r == '-' || r == '_'
+	}), " ")
+}
+
+func boolScore(value bool) float64 {
+	if value {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,265 @@
+package controllers
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/UTDNebula/nebula-api/api/schema"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestScheduleConflicts(t *testing.T) {
+	fullTerm := schema.Meeting{
+		Start_date: time.Date(2024, 8, 19, 0, 0, 0, 0, time.UTC),
+		End_date:   time.Date(2024, 12, 9, 0, 0, 0, 0, time.UTC),
+	}
+	firstHalf := schema.Meeting{
+		Start_date: time.Date(2024, 8, 19, 0, 0, 0, 0, time.UTC),
+		End_date:   time.Date(2024, 10, 11, 0, 0, 0, 0, time.UTC),
+	}
+	secondHalf := schema.Meeting{
+		Start_date: time.Date(2024, 10, 14, 0, 0, 0, 0, time.UTC),
+		End_date:   time.Date(2024, 12, 9, 0, 0, 0, 0, time.UTC),
+	}
+	// A single Saturday
+	oneDay := schema.Meeting{
+		Start_date: time.Date(2024, 9, 7, 0, 0, 0, 0, time.UTC),
+		End_date:   time.Date(2024, 9, 7, 0, 0, 0, 0, time.UTC),
+	}
+
+	meeting := func(base schema.Meeting, days []string, start string, end string) schema.Meeting {
+		base.Meeting_days = days
+		base.Start_time = start
+		base.End_time = end
+		return base
+	}
+
+	testCases := map[string]struct {
+		A        schema.Meeting
+		B        schema.Meeting
+		Expected []string
+	}{
+		"Overlapping": {
+			A:        meeting(fullTerm, []string{"Monday", "Wednesday"}, "10:00am", "11:15am"),
+			B:        meeting(fullTerm, []string{"Wednesday", "Friday"}, "11:00am", "11:50am"),
+			Expected: []string{"Wednesday"},
+		},
+		"BackToBack": {
+			A: meeting(fullTerm, []string{"Monday"}, "10:00am", "11:15am"),
+			B: meeting(fullTerm, []string{"Monday"}, "11:15am", "12:30pm"),
+		},
+		"DifferentDays": {
+			A: meeting(fullTerm, []string{"Tuesday", "Thursday"}, "10:00am", "11:15am"),
+			B: meeting(fullTerm, []string{"Monday", "Wednesday"}, "10:00am", "11:15am"),
+		},
+		"DisjointHalves": {
+			A: meeting(firstHalf, []string{"Monday"}, "10:00am", "11:15am"),
+			B: meeting(secondHalf, []string{"Monday"}, "10:00am", "11:15am"),
+		},
+		"DayNotInRange": {
+			A: meeting(fullTerm, []string{"Friday", "Saturday"}, "9:00am", "5:00pm"),
+			B: meeting(oneDay, []string{"Friday"}, "10:00am", "11:00am"),
+		},
+		"DayInRange": {
+			A:        meeting(fullTerm, []string{"Friday", "Saturday"}, "9:00am", "5:00pm"),
+			B:        meeting(oneDay, []string{"Saturday"}, "10:00am", "11:00am"),
+			Expected: []string{"Saturday"},
+		},
+		"UnknownDates": {
+			A:        meeting(schema.Meeting{}, []string{"Monday"}, "10:00am", "11:15am"),
+			B:        meeting(secondHalf, []string{"Monday"}, "11:00am", "12:15pm"),
+			Expected: []string{"Monday"},
+		},
+		"UnparseableTimes": {
+			A: meeting(fullTerm, []string{"Monday"}, "TBA", "TBA"),
+			B: meeting(fullTerm, []string{"Monday"}, "10:00am", "11:15am"),
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			sections := []schema.Section{
+				{Id: primitive.NewObjectID(), Meetings: []schema.Meeting{tc.A}},
+				{Id: primitive.NewObjectID(), Meetings: []schema.Meeting{tc.B}},
+			}
+			conflicts := scheduleConflicts(sections)
+			if len(tc.Expected) == 0 {
+				if len(conflicts) != 0 {
+					t.Errorf("Expected no conflicts, got %v", conflicts)
+				}
+				return
+			}
+			if len(conflicts) != 1 {
+				t.Fatalf("Expected 1 conflict, got %v", conflicts)
+			}
+			if len(conflicts[0].Days) != len(tc.Expected) || conflicts[0].Days[0] != tc.Expected[0] {
+				t.Errorf("Expected days %v, got %v", tc.Expected, conflicts[0].Days)
+			}
+			if conflicts[0].Sections != [2]primitive.ObjectID{sections[0].Id, sections[1].Id} {
+				t.Errorf("Expected sections %v and %v, got %v", sections[0].Id, sections[1].Id, conflicts[0].Sections)
+			}
+		})
+	}
+}
+
+func TestGenerateSchedules(t *testing.T) {
+	candidate := func(course int, mode string, days []string, start string, end string) scheduleCandidate {
+		section := schema.Section{
+			Id:               primitive.NewObjectID(),
+			Instruction_mode: mode,
+			Meetings:         []schema.Meeting{{Meeting_days: days, Start_time: start, End_time: end}},
+		}
+		return scheduleCandidate{course: course, section: section, intervals: meetingIntervals(section)}
+	}
+
+	early := candidate(0, "Face-to-Face", []string{"Monday", "Wednesday"}, "8:30am", "9:45am")
+	late := candidate(0, "Face-to-Face", []string{"Tuesday", "Thursday"}, "1:00pm", "2:15pm")
+	friday := candidate(1, "Face-to-Face", []string{"Monday", "Wednesday", "Friday"}, "11:00am", "11:50am")
+	clashing := candidate(1, "Face-to-Face", []string{"Tuesday"}, "1:30pm", "2:45pm")
+	online := candidate(1, "Online", nil, "", "")
+
+	schedules, truncated := searchSchedules([][]scheduleCandidate{{early, late}, {friday, clashing, online}})
+	if truncated {
+		t.Fatal("Expected the search to finish")
+	}
+	// Every pairing except late with clashing
+	if len(schedules) != 5 {
+		t.Fatalf("Expected 5 schedules, got %d", len(schedules))
+	}
+	for _, schedule := range schedules {
+		if schedule[0].course != 0 || schedule[1].course != 1 {
+			t.Errorf("Expected sections in course order, got %d then %d", schedule[0].course, schedule[1].course)
+		}
+	}
+
+	preferred, err := parseScheduleConstraints(schema.ScheduleConstraints{
+		Earliest_start: "10:00am",
+		Days_off:       []string{"Friday"},
+	}, []string{"CS 3345", "CS 3354"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	best := rankSchedules(schedules, preferred, 1)[0]
+	if best[0].section.Id != late.section.Id || best[1].section.Id != online.section.Id {
+		t.Errorf("Expected the late and online sections to rank first")
+	}
+	if score := preferred.score(best); score != 1 {
+		t.Errorf("Expected a score of 1, got %f", score)
+	}
+	// Half of the meetings start early and Friday isn't free
+	if score := preferred.score([]scheduleCandidate{early, friday}); score != 0.25 {
+		t.Errorf("Expected a score of 0.25, got %f", score)
+	}
+
+	required, err := parseScheduleConstraints(schema.ScheduleConstraints{Modalities: []string{"face to face"}}, []string{"CS 3345", "CS 3354"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !required.allows(friday) || required.allows(online) {
+		t.Errorf("Expected only face-to-face sections to be allowed")
+	}
+
+	if _, err := parseScheduleConstraints(schema.ScheduleConstraints{Days_off: []string{"Someday"}}, nil); err == nil {
+		t.Errorf("Expected an error for an invalid day")
+	}
+
+	// Professors only constrain the course they're given for
+	professor := primitive.NewObjectID()
+	late.section.Professors = []primitive.ObjectID{professor}
+	required, err = parseScheduleConstraints(schema.ScheduleConstraints{
+		Professors: map[string][]primitive.ObjectID{"cs3345": {professor}},
+	}, []string{"CS 3345", "CS 3354"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !required.allows(late) || required.allows(early) || !required.allows(friday) {
+		t.Errorf("Expected only the professor's section of the first course to be required")
+	}
+	if score := required.score([]scheduleCandidate{early, friday}); score != 0 {
+		t.Errorf("Expected a score of 0 without the professor, got %f", score)
+	}
+	if _, err := parseScheduleConstraints(schema.ScheduleConstraints{
+		Professors: map[string][]primitive.ObjectID{"MATH 2418": {professor}},
+	}, []string{"CS 3345"}); err == nil {
+		t.Errorf("Expected an error for professors of a course which isn't requested")
+	}
+}
+
+func TestParseCourseCodes(t *testing.T) {
+	codes, err := parseCourseCodes([]string{"CS 3345", "cs3354", "MATH  2418", "CS 3345"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{"CS 3345", "CS 3354", "MATH 2418"}
+	if len(codes) != len(expected) {
+		t.Fatalf("Expected %v, got %v", expected, codes)
+	}
+	for i := range expected {
+		if codes[i] != expected[i] {
+			t.Errorf("Expected %v, got %v", expected, codes)
+		}
+	}
+
+	if _, err := parseCourseCodes([]string{"Data Structures"}); err == nil {
+		t.Errorf("Expected an error for an invalid course code")
+	}
+	if _, err := parseCourseCodes(nil); err == nil {
+		t.Errorf("Expected an error for no courses")
+	}
+}
+
+func TestScheduleTransitions(t *testing.T) {
+	// JSOM and ECSS are about 400 meters apart, ECSN has no known location
+	matrix := walkingMatrix{coordinates: map[string][2]float64{
+		"JSOM": {32.98464, -96.74672},
+		"ECSS": {32.98612, -96.75065},
+	}}
+
+	meeting := func(days []string, start string, end string, building string) schema.Meeting {
+		return schema.Meeting{Meeting_days: days, Start_time: start, End_time: end, Location: schema.Location{Building: building}}
+	}
+	section := func(meetings ...schema.Meeting) schema.Section {
+		return schema.Section{Id: primitive.NewObjectID(), Meetings: meetings}
+	}
+
+	jsom := section(meeting([]string{"Monday", "Wednesday"}, "10:00am", "11:15am", "JSOM"))
+	ecss := section(meeting([]string{"Monday", "Wednesday"}, "11:30am", "12:45pm", "ECSS"))
+	ecsn := section(meeting([]string{"Monday"}, "12:50pm", "2:05pm", "ECSN"))
+	lab := section(meeting([]string{"Wednesday"}, "12:50pm", "1:40pm", "JSOM"))
+	seminar := section(meeting([]string{"Monday"}, "2:30pm", "3:20pm", "JSOM"))
+	evening := section(meeting([]string{"Monday"}, "7:00pm", "8:15pm", "JSOM"))
+
+	transitions := scheduleTransitions([]schema.Section{jsom, ecss, ecsn, lab, seminar, evening}, matrix)
+
+	expected := []struct {
+		From   primitive.ObjectID
+		To     primitive.ObjectID
+		Days   []string
+		Status string
+	}{
+		{From: jsom.Id, To: ecss.Id, Days: []string{"Monday", "Wednesday"}, Status: transitionOK},
+		{From: ecss.Id, To: ecsn.Id, Days: []string{"Monday"}, Status: transitionTight},
+		{From: ecss.Id, To: lab.Id, Days: []string{"Wednesday"}, Status: transitionInfeasible},
+		{From: ecsn.Id, To: seminar.Id, Days: []string{"Monday"}, Status: transitionUnknown},
+	}
+	if len(transitions) != len(expected) {
+		t.Fatalf("Expected %d transitions, got %v", len(expected), transitions)
+	}
+	for i, e := range expected {
+		transition := transitions[i]
+		if transition.Sections != [2]primitive.ObjectID{e.From, e.To} || !slices.Equal(transition.Days, e.Days) || transition.Status != e.Status {
+			t.Errorf("Expected transition %d to be %v, got %+v", i, e, transition)
+		}
+	}
+
+	if minutes, _ := matrix.walk("jsom", "ecss"); minutes == nil || *minutes != 7 {
+		t.Errorf("Expected a 7 minute walk from JSOM to ECSS, got %v", minutes)
+	}
+	if minutes, distance := matrix.walk("ECSS", "ECSN"); minutes == nil || *minutes != 3 || distance != nil {
+		t.Errorf("Expected the overridden 3 minute walk without a distance, got %v %v", minutes, distance)
+	}
+	if minutes, _ := matrix.walk("JSOM", "SCI"); minutes != nil {
+		t.Errorf("Expected no estimate to a building without a location, got %v", *minutes)
+	}
+}
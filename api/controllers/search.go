@@ -0,0 +1,435 @@
+package controllers
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/UTDNebula/nebula-api/api/configs"
+	"github.com/UTDNebula/nebula-api/api/schema"
+)
+
+// Number of hits of each type returned by default, and at most
+const (
+	defaultSearchLimit = 5
+	maxSearchLimit     = 20
+)
+
+// Sources are asked for more candidates than the limit so local scoring can pick the best ones
+const searchCandidateFactor = 5
+
+// Types of search hits
+const (
+	searchCourse    = "course"
+	searchProfessor = "professor"
+	searchClub      = "club"
+	searchDiscount  = "discount"
+	searchRoom      = "room"
+)
+
+// searchSource finds the hits of one type matching a query, up to the limit.
+// Scores only need to be comparable between hits of the same source.
+type searchSource func(ctx context.Context, q string, limit int) ([]schema.SearchHit, error)
+
+// A type of hit along with where to search for it
+type searchType struct {
+	kind   string
+	search searchSource
+}
+
+// Types searched by the unified search, in the order ties between them are broken
+var searchSources = []searchType{
+	{kind: searchCourse, search: searchCourses},
+	{kind: searchProfessor, search: searchProfessors},
+	{kind: searchClub, search: searchClubs},
+	{kind: searchDiscount, search: searchDiscounts},
+	{kind: searchRoom, search: searchRooms},
+}
+
+// Course codes such as "CS 1337", "cs1337" or just "CS"
+var courseCodePattern = regexp.MustCompile(`^([a-z]{2,4})\s*(\d[0-9v]{0,3})?$`)
+
+// @Id				search
+// @Router			/search [get]
+// @Tags			Other
+// @Description	"Searches courses, professors, clubs, discounts and rooms at once, returning the hits of every type ranked together. Scores are normalized per type and blended with how closely each hit's title matches the query, so they're comparable across types. Types whose search fails are listed rather than failing the whole search."
+// @Produce		json
+// @Param			q		query		string										true	"Search string"
+// @Param			types	query		string										false	"Types to search, comma-separated, out of course, professor, club, discount and room, defaults to all of them"
+// @Param			limit	query		number										false	"Maximum number of hits of each type, defaults to 5, at most 20"
+// @Success		200		{object}	schema.APIResponse[schema.SearchResults]	"Hits of every type, most relevant first"
+// @Failure		500		{object}	schema.APIResponse[string]					"A string describing the error"
+// @Failure		400		{object}	schema.APIResponse[string]					"A string describing the error"
+func Search(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	q := strings.Join(strings.Fields(c.Query("q")), " ")
+	if q == "" {
+		respond(c, http.StatusBadRequest, "error", "q is required")
+		return
+	}
+
+	limit := defaultSearchLimit
+	if value := c.Query("limit"); value != "" {
+		var err error
+		if limit, err = strconv.Atoi(value); err != nil || limit < 1 {
+			respond(c, http.StatusBadRequest, "error", "limit must be a positive integer")
+			return
+		}
+		limit = min(limit, maxSearchLimit)
+	}
+
+	var kinds []string
+	if value := c.Query("types"); value != "" {
+		for _, kind := range strings.Split(value, ",") {
+			kind = strings.ToLower(strings.TrimSpace(kind))
+			if !slices.ContainsFunc(searchSources, func(source searchType) bool { return source.kind == kind }) {
+				respond(c, http.StatusBadRequest, "error", fmt.Sprintf("invalid type '%s', expected course, professor, club, discount or room", kind))
+				return
+			}
+			kinds = append(kinds, kind)
+		}
+	}
+
+	// Search every source at once, each writing only to its own slot
+	hits := make([][]schema.SearchHit, len(searchSources))
+	errs := make([]error, len(searchSources))
+	var wg sync.WaitGroup
+	for i, source := range searchSources {
+		if kinds != nil && !slices.Contains(kinds, source.kind) {
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			hits[i], errs[i] = source.search(ctx, q, limit)
+		}()
+	}
+	wg.Wait()
+
+	results := schema.SearchResults{Query: q, Hits: []schema.SearchHit{}, Counts: map[string]int{}, Failed: []string{}}
+	var searchErr error
+	searched := 0
+	for i, source := range searchSources {
+		if kinds != nil && !slices.Contains(kinds, source.kind) {
+			continue
+		}
+		searched++
+		if errs[i] != nil {
+			searchErr = fmt.Errorf("%s search: %w", source.kind, errs[i])
+			// A failing source doesn't fail the whole search
+			logError(c, searchErr)
+			results.Failed = append(results.Failed, source.kind)
+			continue
+		}
+		typeHits := normalizeSearchHits(q, hits[i])
+		results.Hits = append(results.Hits, typeHits...)
+		results.Counts[source.kind] = len(typeHits)
+	}
+	if len(results.Failed) == searched {
+		respondWithInternalError(c, searchErr)
+		return
+	}
+
+	// Stable so ties stay in order of the sources
+	slices.SortStableFunc(results.Hits, func(a, b schema.SearchHit) int {
+		return cmp.Compare(b.Score, a.Score)
+	})
+
+	respond(c, http.StatusOK, "success", results)
+}
+
+// normalizeSearchHits scales the scores of the hits of one source relative to its best hit,
+// then blends them with how closely their titles match the query so that sources scoring on different scales can be compared
+func normalizeSearchHits(q string, hits []schema.SearchHit) []schema.SearchHit {
+	best := 0.0
+	for _, hit := range hits {
+		best = max(best, hit.Score)
+	}
+	normalized := make([]schema.SearchHit, 0, len(hits))
+	for _, hit := range hits {
+		relative := 1.0
+		if best > 0 {
+			relative = hit.Score / best
+		}
+		match := max(searchMatchScore(q, hit.Title), searchMatchScore(q, hit.Subtitle))
+		hit.Score = math.Round((relative+match)/2*10000) / 10000
+		normalized = append(normalized, hit)
+	}
+	return normalized
+}
+
+// searchMatchScore rates how closely text matches a query from 0 to 1, ignoring case and spacing:
+// an exact match scores highest, then text starting with the query, every query word starting a word of the text,
+// text containing the query, and finally some query words starting words of the text
+func searchMatchScore(q string, text string) float64 {
+	q, text = foldSearchText(q), foldSearchText(text)
+	if q == "" || text == "" {
+		return 0
+	}
+	switch {
+	case text == q:
+		return 1
+	case strings.HasPrefix(text, q):
+		return 0.8
+	}
+
+	words := strings.Fields(text)
+	matched := 0
+	queryWords := strings.Fields(q)
+	for _, queryWord := range queryWords {
+		if slices.ContainsFunc(words, func(word string) bool { return strings.HasPrefix(word, queryWord) }) {
+			matched++
+		}
+	}
+	switch {
+	case matched == len(queryWords):
+		return 0.6
+	case strings.Contains(text, q):
+		return 0.4
+	}
+	return 0.3 * float64(matched) / float64(len(queryWords))
+}
+
+// foldSearchText lowercases text and collapses its whitespace
+func foldSearchText(text string) string {
+	return strings.Join(strings.Fields(strings.ToLower(text)), " ")
+}
+
+// containsPattern matches fields containing the text, ignoring case
+func containsPattern(text string) bson.D {
+	return bson.D{{Key: "$regex", Value: regexp.QuoteMeta(text)}, {Key: "$options", Value: "i"}}
+}
+
+// wordPrefixPattern matches fields with a word starting with the text, ignoring case
+func wordPrefixPattern(text string) bson.D {
+	return bson.D{{Key: "$regex", Value: `(^|[\s\-'])` + regexp.QuoteMeta(text)}, {Key: "$options", Value: "i"}}
+}
+
+// topSearchHits sorts hits by score, best first, and keeps up to the limit
+func topSearchHits(hits []schema.SearchHit, limit int) []schema.SearchHit {
+	slices.SortStableFunc(hits, func(a, b schema.SearchHit) int { return cmp.Compare(b.Score, a.Score) })
+	return hits[:min(len(hits), limit)]
+}
+
+// searchCourses finds courses by code or title, keeping only the latest catalog year of each course
+func searchCourses(ctx context.Context, q string, limit int) ([]schema.SearchHit, error) {
+	or := bson.A{bson.M{"title": containsPattern(q)}}
+	if match := courseCodePattern.FindStringSubmatch(strings.ToLower(q)); match != nil {
+		code := bson.M{"subject_prefix": bson.D{{Key: "$regex", Value: "^" + match[1] + "$"}, {Key: "$options", Value: "i"}}}
+		if match[2] != "" {
+			code["course_number"] = bson.D{{Key: "$regex", Value: "^" + match[2]}, {Key: "$options", Value: "i"}}
+		}
+		or = append(or, code)
+	}
+
+	var courses []schema.BasicCourse
+	cursor, err := courseCollection.Find(ctx, bson.M{"$or": or}, options.Find().
+		SetSort(bson.D{{Key: "catalog_year", Value: -1}}).
+		SetLimit(int64(limit*searchCandidateFactor)))
+	if err != nil {
+		return nil, err
+	}
+	if err = cursor.All(ctx, &courses); err != nil {
+		return nil, err
+	}
+
+	hits := make([]schema.SearchHit, 0, len(courses))
+	seen := make(map[string]bool)
+	for _, course := range courses {
+		code := strings.TrimSpace(course.Subject_prefix) + " " + strings.TrimSpace(course.Course_number)
+		if seen[code] {
+			continue
+		}
+		seen[code] = true
+		hits = append(hits, schema.SearchHit{
+			Type:     searchCourse,
+			Id:       course.Id.Hex(),
+			Title:    code,
+			Subtitle: strings.TrimSpace(course.Title),
+			Score:    max(searchMatchScore(q, code), searchMatchScore(q, course.Title)),
+		})
+	}
+	return topSearchHits(hits, limit), nil
+}
+
+// searchProfessors finds professors with a first or last name starting with each word of the query
+func searchProfessors(ctx context.Context, q string, limit int) ([]schema.SearchHit, error) {
+	var and bson.A
+	for _, word := range strings.Fields(q) {
+		and = append(and, bson.M{"$or": bson.A{
+			bson.M{"first_name": wordPrefixPattern(word)},
+			bson.M{"last_name": wordPrefixPattern(word)},
+		}})
+	}
+
+	var professors []schema.BasicProfessor
+	cursor, err := professorCollection.Find(ctx, bson.M{"$and": and}, options.Find().SetLimit(int64(limit*searchCandidateFactor)))
+	if err != nil {
+		return nil, err
+	}
+	if err = cursor.All(ctx, &professors); err != nil {
+		return nil, err
+	}
+
+	hits := make([]schema.SearchHit, 0, len(professors))
+	for _, professor := range professors {
+		name := strings.TrimSpace(professor.First_name) + " " + strings.TrimSpace(professor.Last_name)
+		hits = append(hits, schema.SearchHit{
+			Type:     searchProfessor,
+			Id:       professor.Id.Hex(),
+			Title:    name,
+			Subtitle: strings.TrimSpace(professor.Email),
+			Score:    max(searchMatchScore(q, name), searchMatchScore(q, professor.Last_name)),
+		})
+	}
+	return topSearchHits(hits, limit), nil
+}
+
+// searchClubs finds approved clubs with the same ParadeDB query as the club search, scored by ParadeDB
+func searchClubs(ctx context.Context, q string, limit int) ([]schema.SearchHit, error) {
+	var raw []byte
+	err := configs.ConnectClubsDB().QueryRowContext(ctx, `
+    SELECT jsonb_agg(jsonb_build_object(
+        'id', club.id,
+        'name', club.name,
+        'tags', club.tags,
+        'score', club.score
+      ) ORDER BY club.score DESC)
+    FROM (
+      SELECT id, name, tags, paradedb.score(id) as score FROM club where id @@@
+        paradedb.boolean(
+          should => ARRAY[
+            paradedb.boost(20,paradedb.match('alias',$1,distance=>2)),
+            paradedb.boost(10,paradedb.match('name',$1,distance=>2)),
+            paradedb.boost(1,paradedb.match('description',$1,distance=>1)),
+            paradedb.boost(5,paradedb.match('tags',$1,distance=>1))
+          ]) and id @@@
+        paradedb.const_score(0.0, paradedb.term('approved','approved'::approved_enum))
+      ORDER BY score DESC
+      LIMIT $2
+    ) as club;
+  `, q, limit).Scan(&raw)
+	if err != nil {
+		return nil, err
+	}
+
+	var clubs []struct {
+		Id    string   `json:"id"`
+		Name  string   `json:"name"`
+		Tags  []string `json:"tags"`
+		Score float64  `json:"score"`
+	}
+	if raw != nil {
+		if err = json.Unmarshal(raw, &clubs); err != nil {
+			return nil, err
+		}
+	}
+
+	hits := make([]schema.SearchHit, 0, len(clubs))
+	for _, club := range clubs {
+		hits = append(hits, schema.SearchHit{
+			Type:     searchClub,
+			Id:       club.Id,
+			Title:    strings.TrimSpace(club.Name),
+			Subtitle: strings.Join(club.Tags, ", "),
+			Score:    club.Score,
+		})
+	}
+	return hits, nil
+}
+
+// searchDiscounts finds discounts with the same Atlas fuzzy search as the discount search, scored by Atlas
+func searchDiscounts(ctx context.Context, q string, limit int) ([]schema.SearchHit, error) {
+	pipeline := append(buildFuzzySearchPipeline(q),
+		bson.D{{Key: "$limit", Value: limit}},
+		bson.D{{Key: "$addFields", Value: bson.M{"score": bson.M{"$meta": "searchScore"}}}},
+	)
+
+	var discounts []struct {
+		schema.DiscountProgram `bson:",inline"`
+		Score                  float64 `bson:"score"`
+	}
+	cursor, err := discountCollection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	if err = cursor.All(ctx, &discounts); err != nil {
+		return nil, err
+	}
+
+	hits := make([]schema.SearchHit, 0, len(discounts))
+	for _, discount := range discounts {
+		hits = append(hits, schema.SearchHit{
+			Type:     searchDiscount,
+			Id:       discount.Id.Hex(),
+			Title:    strings.TrimSpace(discount.Business),
+			Subtitle: strings.TrimSpace(discount.Discount),
+			Score:    discount.Score,
+		})
+	}
+	return hits, nil
+}
+
+// searchRooms finds schedulable rooms whose building and room number match the query, such as "ECSS 2.4"
+func searchRooms(ctx context.Context, q string, limit int) ([]schema.SearchHit, error) {
+	words := strings.Fields(q)
+	if len(words) == 0 {
+		return []schema.SearchHit{}, nil
+	}
+
+	// Only the buildings starting with the first word can match
+	buildingRooms, err := queryBuildingRooms(ctx, buildingNameFilter(words[0], true))
+	if err != nil {
+		return nil, err
+	}
+	return roomSearchHits(q, buildingRooms, limit), nil
+}
+
+// roomSearchHits scores the rooms of the buildings against the query, keeping those whose building starts with its first word
+func roomSearchHits(q string, buildingRooms []schema.BuildingRooms, limit int) []schema.SearchHit {
+	words := strings.Fields(strings.ToUpper(q))
+	if len(words) == 0 {
+		return []schema.SearchHit{}
+	}
+
+	hits := make([]schema.SearchHit, 0)
+	for _, building := range buildingRooms {
+		abbreviation := strings.ToUpper(strings.TrimSpace(building.Building))
+		if !strings.HasPrefix(abbreviation, words[0]) {
+			continue
+		}
+		for _, room := range building.Rooms {
+			number := strings.TrimSpace(room.Room)
+			if len(words) > 1 && !strings.HasPrefix(strings.ToUpper(number), strings.Join(words[1:], "")) {
+				continue
+			}
+			name := abbreviation + " " + number
+			hits = append(hits, schema.SearchHit{
+				Type:     searchRoom,
+				Id:       name,
+				Title:    name,
+				Subtitle: fmt.Sprintf("Capacity %d", room.Capacity),
+				Score:    searchMatchScore(q, name),
+			})
+		}
+	}
+	// Rooms of the same building score the same, so keep them in order of their numbers
+	slices.SortStableFunc(hits, func(a, b schema.SearchHit) int { return strings.Compare(a.Title, b.Title) })
+	return topSearchHits(hits, limit)
+}
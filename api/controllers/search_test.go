@@ -0,0 +1,65 @@
+package controllers
+
+import (
+	"testing"
+
+	"github.com/UTDNebula/nebula-api/api/schema"
+)
+
+func TestSearchMatchScore(t *testing.T) {
+	testCases := map[string]struct {
+		Query    string
+		Text     string
+		Expected float64
+	}{
+		"Exact":          {Query: "cs 1337", Text: "CS  1337", Expected: 1},
+		"Prefix":         {Query: "data str", Text: "Data Structures", Expected: 0.8},
+		"WordPrefixes":   {Query: "struct algo", Text: "Data Structures and Algorithmic Analysis", Expected: 0.6},
+		"Contains":       {Query: "ructure", Text: "Data Structures", Expected: 0.4},
+		"SomeWords":      {Query: "data science", Text: "Data Structures", Expected: 0.15},
+		"NoMatch":        {Query: "chemistry", Text: "Data Structures", Expected: 0},
+		"EmptyText":      {Query: "data", Text: "", Expected: 0},
+		"WhitespaceOnly": {Query: "   ", Text: "Data", Expected: 0},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			if result := searchMatchScore(tc.Query, tc.Text); result != tc.Expected {
+				t.Errorf("Expected %v, got %v", tc.Expected, result)
+			}
+		})
+	}
+}
+
+func TestNormalizeSearchHits(t *testing.T) {
+	// Discounts scored by Atlas on an unbounded scale
+	hits := normalizeSearchHits("pizza", []schema.SearchHit{
+		{Title: "Pizza Hut", Subtitle: "10% off", Score: 12},
+		{Title: "Mario's Italian", Subtitle: "Free pizza slice", Score: 3},
+	})
+	if hits[0].Score != 0.9 {
+		t.Errorf("Expected the best hit starting with the query to score 0.9, got %v", hits[0].Score)
+	}
+	if hits[1].Score != 0.425 {
+		t.Errorf("Expected a quarter of the best score blended with a word match to score 0.425, got %v", hits[1].Score)
+	}
+}
+
+func TestRoomSearchHits(t *testing.T) {
+	buildingRooms := []schema.BuildingRooms{
+		{Building: "ECSS", Rooms: []schema.Room{{Room: "2.410", Capacity: 40}, {Room: "2.415"}, {Room: "3.910"}}},
+		{Building: "ECSW", Rooms: []schema.Room{{Room: "1.315"}}},
+		{Building: "JSOM", Rooms: []schema.Room{{Room: "2.410"}}},
+	}
+
+	hits := roomSearchHits("ecss 2.41", buildingRooms, 5)
+	if len(hits) != 2 || hits[0].Id != "ECSS 2.410" || hits[1].Id != "ECSS 2.415" {
+		t.Fatalf("Expected ECSS 2.410 and 2.415, got %v", hits)
+	}
+	if hits[0].Subtitle != "Capacity 40" || hits[0].Score != 0.8 {
+		t.Errorf("Expected a room starting with the query with its capacity, got %v", hits[0])
+	}
+	if hits := roomSearchHits("ecs", buildingRooms, 3); len(hits) != 3 {
+		t.Errorf("Expected rooms of every building starting with ECS up to the limit, got %v", hits)
+	}
+}
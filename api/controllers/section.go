@@ -43,6 +43,9 @@ var sectionCollection *mongo.Collection = configs.GetCollection("sections")
 // @Param			meetings.location.building		query		string									false	"The building of one of the section's meetings"
 // @Param			meetings.location.room			query		string									false	"The room of one of the section's meetings"
 // @Param			meetings.location.map_uri		query		string									false	"A hyperlink to the UTD room locator of one of the section's meetings"
+// @Param			meetings.start_minutes			query		string									false	"The start of one of the section's meetings in minutes since midnight or as a time (e.g. 13:00), append [gt], [gte], [lt], [lte] or [ne] to compare"
+// @Param			meetings.end_minutes			query		string									false	"The end of one of the section's meetings in minutes since midnight or as a time (e.g. 14:15), append [gt], [gte], [lt], [lte] or [ne] to compare"
+// @Param			meetings.days					query		string									false	"The days of one of the section's meetings (e.g. TR), append [all] or [any] to match meetings on at least all or any of the days"
 // @Param			core_flags						query		string									false	"One of core requirement codes this section fulfills"
 // @Param			syllabus_uri					query		string									false	"A link to the syllabus on the web"
 // @Success		200								{object}	schema.APIResponse[[]schema.Section]	"A list of sections"
@@ -148,6 +151,9 @@ func SectionById(c *gin.Context) {
 // @Param			meetings.location.building		query		string								false	"The building of one of the section's meetings"
 // @Param			meetings.location.room			query		string								false	"The room of one of the section's meetings"
 // @Param			meetings.location.map_uri		query		string								false	"A hyperlink to the UTD room locator of one of the section's meetings"
+// @Param			meetings.start_minutes			query		string								false	"The start of one of the section's meetings in minutes since midnight or as a time (e.g. 13:00), append [gt], [gte], [lt], [lte] or [ne] to compare"
+// @Param			meetings.end_minutes			query		string								false	"The end of one of the section's meetings in minutes since midnight or as a time (e.g. 14:15), append [gt], [gte], [lt], [lte] or [ne] to compare"
+// @Param			meetings.days					query		string								false	"The days of one of the section's meetings (e.g. TR), append [all] or [any] to match meetings on at least all or any of the days"
 // @Param			core_flags						query		string								false	"One of core requirement codes this section fulfills"
 // @Param			syllabus_uri					query		string								false	"A link to the syllabus on the web"
 // @Success		200								{object}	schema.APIResponse[[]schema.Course]	"A list of courses"
@@ -287,6 +293,9 @@ func sectionCourse(flag string, c *gin.Context) {
 // @Param			meetings.location.building		query		string									false	"The building of one of the section's meetings"
 // @Param			meetings.location.room			query		string									false	"The room of one of the section's meetings"
 // @Param			meetings.location.map_uri		query		string									false	"A hyperlink to the UTD room locator of one of the section's meetings"
+// @Param			meetings.start_minutes			query		string									false	"The start of one of the section's meetings in minutes since midnight or as a time (e.g. 13:00), append [gt], [gte], [lt], [lte] or [ne] to compare"
+// @Param			meetings.end_minutes			query		string									false	"The end of one of the section's meetings in minutes since midnight or as a time (e.g. 14:15), append [gt], [gte], [lt], [lte] or [ne] to compare"
+// @Param			meetings.days					query		string									false	"The days of one of the section's meetings (e.g. TR), append [all] or [any] to match meetings on at least all or any of the days"
 // @Param			core_flags						query		string									false	"One of core requirement codes this section fulfills"
 // @Param			syllabus_uri					query		string									false	"A link to the syllabus on the web"
 // @Success		200								{object}	schema.APIResponse[[]schema.Professor]	"A list of professor"
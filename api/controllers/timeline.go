@@ -0,0 +1,219 @@
+package controllers
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/UTDNebula/nebula-api/api/schema"
+
+	"github.com/gin-gonic/gin"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Fields of a section needed to describe it in a timeline
+var timelineSectionProjection = options.Find().SetProjection(bson.M{
+	"section_number":   1,
+	"course_reference": 1,
+	"professors":       1,
+})
+
+// @Id				timeline
+// @Router			/timeline/{date} [get]
+// @Tags			Events
+// @Description	"Returns the sections and events from CourseBook, Astra, Mazevo and the Comet Calendar on the specified date, merged and sorted by time. Cancelled and denied Astra and Mazevo events are included along with their state."
+// @Produce		json
+// @Param			date	path		string										true	"ISO date of the events to get"
+// @Success		200		{object}	schema.APIResponse[[]schema.UnifiedEvent]	"All events on the specified date"
+// @Failure		500		{object}	schema.APIResponse[string]					"A string describing the error"
+// @Failure		400		{object}	schema.APIResponse[string]					"A string describing the error"
+func Timeline(c *gin.Context) {
+	respondWithTimeline(c, "", "")
+}
+
+// @Id				timelineByBuilding
+// @Router			/timeline/{date}/{building} [get]
+// @Tags			Events
+// @Description	"Returns the sections and events from every source on the specified date in the specified building, merged and sorted by time. A building without events that day has an empty list, an unknown building is 404 with the available buildings."
+// @Produce		json
+// @Param			date		path		string										true	"ISO date of the events to get"
+// @Param			building	path		string										true	"building abbreviation of the event locations"
+// @Success		200			{object}	schema.APIResponse[[]schema.UnifiedEvent]	"All events on the specified date in the specified building"
+// @Failure		500			{object}	schema.APIResponse[string]					"A string describing the error"
+// @Failure		404			{object}	schema.APIResponse[string]					"A string describing the error"
+// @Failure		400			{object}	schema.APIResponse[string]					"A string describing the error"
+func TimelineByBuilding(c *gin.Context) {
+	respondWithTimeline(c, strings.TrimSpace(c.Param("building")), "")
+}
+
+// @Id				timelineByRoom
+// @Router			/timeline/{date}/{building}/{room} [get]
+// @Tags			Events
+// @Description	"Returns the sections and events from every source on the specified date in the specified building and room, merged and sorted by time"
+// @Produce		json
+// @Param			date		path		string										true	"ISO date of the events to get"
+// @Param			building	path		string										true	"building abbreviation of the event location"
+// @Param			room		path		string										true	"room number"
+// @Success		200			{object}	schema.APIResponse[[]schema.UnifiedEvent]	"All events on the specified date in the specified building and room"
+// @Failure		500			{object}	schema.APIResponse[string]					"A string describing the error"
+// @Failure		404			{object}	schema.APIResponse[string]					"A string describing the error"
+// @Failure		400			{object}	schema.APIResponse[string]					"A string describing the error"
+func TimelineByRoom(c *gin.Context) {
+	respondWithTimeline(c, strings.TrimSpace(c.Param("building")), strings.TrimSpace(c.Param("room")))
+}
+
+// respondWithTimeline responds with the unified events of the date parameter, optionally limited to a building and room
+func respondWithTimeline(c *gin.Context, building string, room string) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	day, err := parseEventDate(c.Param("date"))
+	if err != nil {
+		respond(c, http.StatusBadRequest, "error", err.Error())
+		return
+	}
+
+	events, err := findDayUnifiedEvents(ctx, day)
+	if err != nil {
+		respondWithInternalError(c, err)
+		return
+	}
+
+	// Buildings and rooms are looked up like every event source does, knowing those without events too
+	if building != "" {
+		known, err := findBuildingRooms(ctx, c, "")
+		if err != nil {
+			return
+		}
+		hierarchy := timelineHierarchy(day.Format(time.DateOnly), events, known)
+		if room == "" {
+			found, err := findRequestedBuilding(c, hierarchy)
+			if err != nil {
+				return
+			}
+			events = make([]schema.UnifiedEvent, 0)
+			for _, r := range found.Rooms {
+				events = append(events, r.Events...)
+			}
+			sortUnifiedEvents(events)
+		} else {
+			found, err := findRequestedRoom(c, hierarchy)
+			if err != nil {
+				return
+			}
+			events = found.Events
+		}
+	}
+
+	if err = describeSectionEvents(ctx, events); err != nil {
+		respondWithInternalError(c, err)
+		return
+	}
+
+	respond(c, http.StatusOK, "success", events)
+}
+
+// describeSectionEvents fills in the titles and organizers of CourseBook events,
+// which only reference their sections, e.g. "CS 3345.001" taught by its professors
+func describeSectionEvents(ctx context.Context, events []schema.UnifiedEvent) error {
+	var sectionIDs []primitive.ObjectID
+	for _, event := range events {
+		if event.Source != sourceCoursebook {
+			continue
+		}
+		if id, err := primitive.ObjectIDFromHex(event.Id); err == nil {
+			sectionIDs = append(sectionIDs, id)
+		}
+	}
+	if len(sectionIDs) == 0 {
+		return nil
+	}
+
+	var sections []schema.Section
+	cursor, err := sectionCollection.Find(ctx, bson.M{"_id": bson.M{"$in": sectionIDs}}, timelineSectionProjection)
+	if err != nil {
+		return err
+	}
+	if err = cursor.All(ctx, &sections); err != nil {
+		return err
+	}
+
+	var courseIDs, professorIDs []primitive.ObjectID
+	for _, section := range sections {
+		courseIDs = append(courseIDs, section.Course_reference)
+		professorIDs = append(professorIDs, section.Professors...)
+	}
+
+	courses, err := findEquivalentCandidates(ctx, bson.M{"_id": bson.M{"$in": courseIDs}})
+	if err != nil {
+		return err
+	}
+	var professors []schema.BasicProfessor
+	if len(professorIDs) > 0 {
+		cursor, err = professorCollection.Find(ctx, bson.M{"_id": bson.M{"$in": professorIDs}})
+		if err != nil {
+			return err
+		}
+		if err = cursor.All(ctx, &professors); err != nil {
+			return err
+		}
+	}
+
+	codes := make(map[primitive.ObjectID]string, len(courses))
+	for _, course := range courses {
+		codes[course.Id] = courseCode(course.Subject_prefix, course.Course_number)
+	}
+	names := make(map[primitive.ObjectID]string, len(professors))
+	for _, professor := range professors {
+		names[professor.Id] = strings.TrimSpace(professor.First_name + " " + professor.Last_name)
+	}
+
+	titles := make(map[string]string, len(sections))
+	organizers := make(map[string]string, len(sections))
+	for _, section := range sections {
+		titles[section.Id.Hex()] = section.Section_number
+		if code, ok := codes[section.Course_reference]; ok {
+			titles[section.Id.Hex()] = code + "." + section.Section_number
+		}
+		var instructors []string
+		for _, professorID := range section.Professors {
+			if name := names[professorID]; name != "" {
+				instructors = append(instructors, name)
+			}
+		}
+		organizers[section.Id.Hex()] = strings.Join(instructors, ", ")
+	}
+
+	for i := range events {
+		if events[i].Source == sourceCoursebook {
+			events[i].Title = titles[events[i].Id]
+			events[i].Organizer = organizers[events[i].Id]
+		}
+	}
+	return nil
+}
+
+// timelineHierarchy groups unified events by building and room, along with the known buildings and rooms without events
+func timelineHierarchy(date string, events []schema.UnifiedEvent, known []schema.BuildingRooms) schema.MultiBuildingEvents[schema.UnifiedEvent] {
+	knownRooms := schema.MultiBuildingEvents[schema.UnifiedEvent]{Buildings: make([]schema.SingleBuildingEvents[schema.UnifiedEvent], 0, len(known))}
+	for _, building := range known {
+		rooms := make([]schema.RoomEvents[schema.UnifiedEvent], 0, len(building.Rooms))
+		for _, room := range building.Rooms {
+			rooms = append(rooms, schema.RoomEvents[schema.UnifiedEvent]{Room: room.Room})
+		}
+		knownRooms.Buildings = append(knownRooms.Buildings, schema.SingleBuildingEvents[schema.UnifiedEvent]{Building: building.Building, Rooms: rooms})
+	}
+
+	eventRooms := schema.MultiBuildingEvents[schema.UnifiedEvent]{Buildings: make([]schema.SingleBuildingEvents[schema.UnifiedEvent], 0, len(events))}
+	for _, event := range events {
+		eventRooms.Buildings = append(eventRooms.Buildings, schema.SingleBuildingEvents[schema.UnifiedEvent]{
+			Building: event.Building,
+			Rooms:    []schema.RoomEvents[schema.UnifiedEvent]{{Room: event.Room, Events: []schema.UnifiedEvent{event}}},
+		})
+	}
+	return mergeEvents(date, knownRooms, eventRooms)
+}
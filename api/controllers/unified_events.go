@@ -0,0 +1,231 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/UTDNebula/nebula-api/api/schema"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Sources of unified events
+const (
+	sourceCoursebook    = "coursebook"
+	sourceAstra         = "astra"
+	sourceMazevo        = "mazevo"
+	sourceCometCalendar = "comet_calendar"
+)
+
+// Campus time, event times stored without a zone are in it
+var campusLocation = schema.CampusLocation
+
+// parseEventDate parses an ISO date such as "2025-09-02" as the start of that day in campus time
+func parseEventDate(date string) (time.Time, error) {
+	day, err := time.ParseInLocation(time.DateOnly, strings.TrimSpace(date), campusLocation)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date '%s', expected YYYY-MM-DD", date)
+	}
+	return day, nil
+}
+
+// campusToday returns the start of the current day in campus time
+func campusToday() time.Time {
+	now := time.Now().In(campusLocation)
+	return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, campusLocation)
+}
+
+// atMinutes returns the time the given minutes after midnight of day, in campus time
+func atMinutes(day time.Time, minutes int) time.Time {
+	return time.Date(day.Year(), day.Month(), day.Day(), 0, minutes, 0, 0, campusLocation)
+}
+
+// parseEventTime parses a timestamp from any of the event sources.
+// Times of day without a date, such as those of section meetings, are taken to be on the given day.
+func parseEventTime(day time.Time, value string) (time.Time, error) {
+	if parsed, err := schema.ParseEventTimestamp(value); err == nil {
+		return parsed, nil
+	}
+	minutes, err := schema.ParseClockTime(value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid event time '%s'", value)
+	}
+	return atMinutes(day, minutes), nil
+}
+
+// parseEventSpan parses the start and end of an event, returning false if either is missing or invalid
+func parseEventSpan(day time.Time, start string, end string) (time.Time, time.Time, bool) {
+	startTime, err := parseEventTime(day, start)
+	if err != nil {
+		return time.Time{}, time.Time{}, false
+	}
+	endTime, err := parseEventTime(day, end)
+	if err != nil || !endTime.After(startTime) {
+		return time.Time{}, time.Time{}, false
+	}
+	return startTime, endTime, true
+}
+
+// findDayEvents retrieves the events of a date from one of the event collections, with no buildings if the date has none.
+// Buildings and rooms listed more than once under slightly different names are merged.
+func findDayEvents[T any](ctx context.Context, collection *mongo.Collection, date string) (schema.MultiBuildingEvents[T], error) {
+	var events schema.MultiBuildingEvents[T]
+	err := collection.FindOne(ctx, bson.M{"date": date}).Decode(&events)
+	if err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
+		return events, err
+	}
+	return mergeEvents(date, events), nil
+}
+
+// collectUnifiedEvents converts the events of a date from one of the event sources into unified events.
+// The event function fills in everything but the source, building and room, and returns false for events whose span can't be determined.
+// The occupied span defaults to the span of the event.
+func collectUnifiedEvents[T any](events schema.MultiBuildingEvents[T], source string, day time.Time, convert func(day time.Time, event T) (schema.UnifiedEvent, bool)) []schema.UnifiedEvent {
+	var unified []schema.UnifiedEvent
+	for _, building := range events.Buildings {
+		for _, room := range building.Rooms {
+			for _, event := range room.Events {
+				unifiedEvent, ok := convert(day, event)
+				if !ok {
+					continue
+				}
+				unifiedEvent.Source = source
+				if unifiedEvent.Occupied_start.IsZero() || unifiedEvent.Occupied_end.IsZero() {
+					unifiedEvent.Occupied_start, unifiedEvent.Occupied_end = unifiedEvent.Start, unifiedEvent.End
+				}
+				unifiedEvent.Building = strings.TrimSpace(building.Building)
+				unifiedEvent.Room = strings.TrimSpace(room.Room)
+				unified = append(unified, unifiedEvent)
+			}
+		}
+	}
+	return unified
+}
+
+// findDayUnifiedEvents merges the events of a day from every event source, ordered by time.
+// Cancelled and denied Astra bookings and Mazevo events are included along with their state, see occupiesRoom.
+func findDayUnifiedEvents(ctx context.Context, day time.Time) ([]schema.UnifiedEvent, error) {
+	date := day.Format(time.DateOnly)
+	unified := make([]schema.UnifiedEvent, 0)
+
+	sections, err := findDayEvents[schema.SectionWithTime](ctx, eventsCollection, date)
+	if err != nil {
+		return nil, err
+	}
+	unified = append(unified, collectUnifiedEvents(sections, sourceCoursebook, day, sectionUnifiedEvent)...)
+
+	astra, err := findDayEvents[schema.AstraEvent](ctx, astraCollection, date)
+	if err != nil {
+		return nil, err
+	}
+	unified = append(unified, collectUnifiedEvents(astra, sourceAstra, day, astraUnifiedEvent)...)
+
+	mazevo, err := findDayEvents[schema.MazevoEvent](ctx, mazevoCollection, date)
+	if err != nil {
+		return nil, err
+	}
+	unified = append(unified, collectUnifiedEvents(mazevo, sourceMazevo, day, mazevoUnifiedEvent)...)
+
+	calendar, err := findDayEvents[schema.Event](ctx, cometCalendarCollection, date)
+	if err != nil {
+		return nil, err
+	}
+	unified = append(unified, collectUnifiedEvents(calendar, sourceCometCalendar, day, cometCalendarUnifiedEvent)...)
+
+	sortUnifiedEvents(unified)
+	return unified, nil
+}
+
+// Conversions of the events of each source into unified events, for collectUnifiedEvents
+func sectionUnifiedEvent(day time.Time, event schema.SectionWithTime) (schema.UnifiedEvent, bool) {
+	start, end, ok := sectionEventSpan(day, event)
+	return schema.UnifiedEvent{Id: event.Section.Hex(), Start: start, End: end}, ok
+}
+
+func astraUnifiedEvent(day time.Time, event schema.AstraEvent) (schema.UnifiedEvent, bool) {
+	start, end, ok := astraEventSpan(day, event)
+	return schema.UnifiedEvent{Title: stringValue(event.ActivityName), Start: start, End: end, State: string(event.State)}, ok
+}
+
+func mazevoUnifiedEvent(day time.Time, event schema.MazevoEvent) (schema.UnifiedEvent, bool) {
+	start, end, ok := mazevoEventSpan(day, event)
+	if !ok {
+		return schema.UnifiedEvent{}, false
+	}
+	// Vacant events keep the default occupied span of the event itself
+	occupiedStart, occupiedEnd, _ := mazevoOccupancySpan(day, event)
+	organizer := stringValue(event.OrganizationName)
+	if organizer == "" {
+		organizer = stringValue(event.ContactName)
+	}
+	return schema.UnifiedEvent{
+		Title:          stringValue(event.EventName),
+		Organizer:      organizer,
+		Start:          start,
+		End:            end,
+		State:          stringValue(event.StatusDescription),
+		Occupied_start: occupiedStart,
+		Occupied_end:   occupiedEnd,
+	}, true
+}
+
+func cometCalendarUnifiedEvent(day time.Time, event schema.Event) (schema.UnifiedEvent, bool) {
+	organizer := event.ContactName
+	if organizer == "" {
+		organizer = strings.Join(event.Department, ", ")
+	}
+	start, end, ok := cometCalendarEventSpan(day, event)
+	return schema.UnifiedEvent{
+		Id:        event.Id.Hex(),
+		Title:     event.Summary,
+		Organizer: organizer,
+		Link:      event.EventWebsite,
+		Start:     start,
+		End:       end,
+	}, ok
+}
+
+// occupiesRoom reports whether a unified event occupies its room, which cancelled and denied events don't
+func occupiesRoom(event schema.UnifiedEvent) bool {
+	switch event.Source {
+	case sourceAstra:
+		return !astraStateVacant(schema.AstraState(event.State))
+	case sourceMazevo:
+		return !mazevoStatusVacant(event.State)
+	}
+	return true
+}
+
+// sortUnifiedEvents orders events by time, then by location and title so the order is deterministic
+func sortUnifiedEvents(events []schema.UnifiedEvent) {
+	slices.SortFunc(events, func(a, b schema.UnifiedEvent) int {
+		if byStart := a.Start.Compare(b.Start); byStart != 0 {
+			return byStart
+		}
+		if byEnd := a.End.Compare(b.End); byEnd != 0 {
+			return byEnd
+		}
+		if byRoom := strings.Compare(roomKey(a.Building, a.Room), roomKey(b.Building, b.Room)); byRoom != 0 {
+			return byRoom
+		}
+		return strings.Compare(a.Title, b.Title)
+	})
+}
+
+// roomKey identifies a room across the event sources, which differ in case and spacing
+func roomKey(building string, room string) string {
+	return strings.ToUpper(strings.TrimSpace(building)) + "|" + strings.ToUpper(strings.TrimSpace(room))
+}
+
+// stringValue dereferences the optional string fields of the event sources
+func stringValue(value *string) string {
+	if value == nil {
+		return ""
+	}
+	return *value
+}
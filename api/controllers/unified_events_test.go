@@ -0,0 +1,146 @@
+package controllers
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/UTDNebula/nebula-api/api/schema"
+)
+
+func TestParseEventTime(t *testing.T) {
+	day := time.Date(2025, 9, 2, 0, 0, 0, 0, campusLocation)
+
+	testCases := map[string]struct {
+		Value    string
+		Expected time.Time
+		Invalid  bool
+	}{
+		"RFC3339":   {Value: "2025-09-02T19:00:00Z", Expected: time.Date(2025, 9, 2, 14, 0, 0, 0, campusLocation)},
+		"Local":     {Value: "2025-09-02T14:00:00", Expected: time.Date(2025, 9, 2, 14, 0, 0, 0, campusLocation)},
+		"Space":     {Value: "2025-09-02 14:00", Expected: time.Date(2025, 9, 2, 14, 0, 0, 0, campusLocation)},
+		"ClockTime": {Value: "2:00pm", Expected: time.Date(2025, 9, 2, 14, 0, 0, 0, campusLocation)},
+		"Invalid":   {Value: "afternoon", Invalid: true},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			result, err := parseEventTime(day, tc.Value)
+			if tc.Invalid {
+				if err == nil {
+					t.Errorf("Expected an error, got %v", result)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !result.Equal(tc.Expected) {
+				t.Errorf("Expected %v, got %v", tc.Expected, result)
+			}
+		})
+	}
+}
+
+func TestCollectUnifiedEvents(t *testing.T) {
+	day := time.Date(2025, 9, 2, 0, 0, 0, 0, campusLocation)
+	name := func(value string) *string { return &value }
+
+	events := schema.MultiBuildingEvents[schema.MazevoEvent]{
+		Date: "2025-09-02",
+		Buildings: []schema.SingleBuildingEvents[schema.MazevoEvent]{{
+			Building: " ECSW ",
+			Rooms: []schema.RoomEvents[schema.MazevoEvent]{{
+				Room: "1.315",
+				Events: []schema.MazevoEvent{
+					{EventName: name("Hackathon"), ContactName: name("Temoc"), DateTimeStart: name("2025-09-02T18:00:00"), DateTimeEnd: name("2025-09-02T21:00:00")},
+					{EventName: name("Info Session"), OrganizationName: name("Nebula Labs"), DateTimeStart: name("2025-09-02T12:00:00"), DateTimeEnd: name("2025-09-02T13:00:00")},
+					{EventName: name("Missing end"), DateTimeStart: name("2025-09-02T09:00:00")},
+				},
+			}},
+		}},
+	}
+
+	unified := collectUnifiedEvents(events, sourceMazevo, day, func(day time.Time, event schema.MazevoEvent) (schema.UnifiedEvent, bool) {
+		start, end, ok := parseEventSpan(day, stringValue(event.DateTimeStart), stringValue(event.DateTimeEnd))
+		return schema.UnifiedEvent{Title: stringValue(event.EventName), Start: start, End: end}, ok
+	})
+	sortUnifiedEvents(unified)
+
+	if len(unified) != 2 {
+		t.Fatalf("Expected 2 events with valid spans, got %d", len(unified))
+	}
+	if unified[0].Title != "Info Session" || unified[1].Title != "Hackathon" {
+		t.Errorf("Expected events sorted by start, got %s then %s", unified[0].Title, unified[1].Title)
+	}
+	if unified[0].Source != sourceMazevo || unified[0].Building != "ECSW" || unified[0].Room != "1.315" {
+		t.Errorf("Expected the source and trimmed location to be filled in, got %+v", unified[0])
+	}
+}
+
+func TestVacantUnifiedEvents(t *testing.T) {
+	day := time.Date(2025, 9, 2, 0, 0, 0, 0, campusLocation)
+	name := func(value string) *string { return &value }
+
+	astra, ok := astraUnifiedEvent(day, schema.AstraEvent{
+		ActivityName: name("Review Session"), State: schema.AstraStateCancelled,
+		StartDate: name("2025-09-02T14:00:00"), EndDate: name("2025-09-02T15:00:00"),
+	})
+	astra.Source = sourceAstra
+	if !ok || astra.State != "cancelled" || occupiesRoom(astra) {
+		t.Errorf("Expected the cancelled booking to be listed with its state without occupying the room, got %+v", astra)
+	}
+
+	mazevo, ok := mazevoUnifiedEvent(day, schema.MazevoEvent{
+		EventName: name("Gala"), StatusDescription: name("Confirmed"),
+		DateTimeStart: name("2025-09-02T18:00:00"), DateTimeEnd: name("2025-09-02T21:00:00"),
+	})
+	mazevo.Source = sourceMazevo
+	if !ok || mazevo.State != "Confirmed" || !occupiesRoom(mazevo) {
+		t.Errorf("Expected the confirmed event to occupy the room, got %+v", mazevo)
+	}
+	mazevo.State = "Cancelled by requester"
+	if occupiesRoom(mazevo) {
+		t.Error("Expected the cancelled event not to occupy the room")
+	}
+}
+
+func TestEventRangeDates(t *testing.T) {
+	from := time.Date(2025, 9, 29, 0, 0, 0, 0, campusLocation)
+
+	testCases := map[string]struct {
+		To       time.Time
+		Expected []string
+		Invalid  bool
+	}{
+		"SingleDay":   {To: from, Expected: []string{"2025-09-29"}},
+		"AcrossMonth": {To: from.AddDate(0, 0, 3), Expected: []string{"2025-09-29", "2025-09-30", "2025-10-01", "2025-10-02"}},
+		"Reversed":    {To: from.AddDate(0, 0, -1), Invalid: true},
+		"MaximumDays": {To: from.AddDate(0, 0, maxEventRangeDays-1), Expected: nil},
+		"TooLong":     {To: from.AddDate(0, 0, maxEventRangeDays), Invalid: true},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			result, err := eventRangeDates(from, tc.To)
+			if tc.Invalid {
+				if err == nil {
+					t.Errorf("Expected an error, got %v", result)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if tc.Expected == nil {
+				if len(result) != maxEventRangeDays {
+					t.Errorf("Expected %d days, got %d", maxEventRangeDays, len(result))
+				}
+				return
+			}
+			if !slices.Equal(result, tc.Expected) {
+				t.Errorf("Expected %v, got %v", tc.Expected, result)
+			}
+		})
+	}
+}
@@ -0,0 +1,248 @@
+package controllers
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/UTDNebula/nebula-api/api/schema"
+)
+
+// Walking pace in meters per minute, and how much longer paths are than straight lines between buildings
+const (
+	walkingSpeed = 80
+	routeFactor  = 1.3
+)
+
+// Transitions with less than this many spare minutes after walking are tight
+const tightTransitionMinutes = 5
+
+// Meetings further apart than this many minutes aren't transitions
+const maxTransitionGap = 60
+
+// Statuses of schedule transitions
+const (
+	transitionOK         = "ok"
+	transitionTight      = "tight"
+	transitionInfeasible = "infeasible"
+	transitionUnknown    = "unknown"
+)
+
+// Walking minutes between buildings whose paths differ a lot from straight lines, such as those connected indoors
+var walkMinuteOverrides = map[[2]string]int{
+	{"ECSN", "ECSS"}: 3,
+	{"ECSS", "ECSW"}: 4,
+	{"FN", "FO"}:     2,
+}
+
+// Estimates how long it takes to walk between campus buildings
+type walkingMatrix struct {
+	coordinates map[string][2]float64 // latitude and longitude by uppercase building abbreviation
+}
+
+// @Id				buildingsWalking
+// @Router			/buildings/walking [get]
+// @Tags			Other
+// @Description	"Returns the estimated walking minutes and distances between every pair of buildings"
+// @Produce		json
+// @Param			buildings	query		string										false	"Building abbreviations to include, comma-separated, defaults to every building with a known location"
+// @Success		200			{object}	schema.APIResponse[schema.WalkTimeMatrix]	"Walking minutes and distances between the buildings"
+// @Failure		500			{object}	schema.APIResponse[string]					"A string describing the error"
+func BuildingsWalking(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	matrix, err := findWalkingMatrix(ctx, c)
+	if err != nil {
+		return
+	}
+
+	var buildings []string
+	if value := c.Query("buildings"); value != "" {
+		for _, building := range strings.Split(value, ",") {
+			if building = strings.ToUpper(strings.TrimSpace(building)); building != "" && !slices.Contains(buildings, building) {
+				buildings = append(buildings, building)
+			}
+		}
+	} else {
+		for building := range matrix.coordinates {
+			buildings = append(buildings, building)
+		}
+		slices.Sort(buildings)
+	}
+
+	walkTimes := schema.WalkTimeMatrix{
+		Buildings: buildings,
+		Minutes:   make([][]*int, len(buildings)),
+		Distances: make([][]*float64, len(buildings)),
+	}
+	for i, from := range buildings {
+		walkTimes.Minutes[i] = make([]*int, len(buildings))
+		walkTimes.Distances[i] = make([]*float64, len(buildings))
+		for j, to := range buildings {
+			walkTimes.Minutes[i][j], walkTimes.Distances[i][j] = matrix.walk(from, to)
+		}
+	}
+
+	respond(c, http.StatusOK, "success", walkTimes)
+}
+
+// @Id				scheduleTransitions
+// @Router			/schedule/transitions [post]
+// @Tags			Schedule
+// @Description	"Returns every pair of the given sections whose meetings follow each other within an hour on the same days, with whether there's time to walk between them"
+// @Accept			json
+// @Produce		json
+// @Param			body	body		schema.ScheduleSectionsBody						true	"IDs of the sections to check"
+// @Success		200		{object}	schema.APIResponse[[]schema.ScheduleTransition]	"All back-to-back meetings, the ones there isn't enough time to walk between being infeasible and those with less than 5 minutes to spare tight"
+// @Failure		500		{object}	schema.APIResponse[string]						"A string describing the error"
+// @Failure		404		{object}	schema.APIResponse[string]						"A string describing the error"
+// @Failure		400		{object}	schema.APIResponse[string]						"A string describing the error"
+func ScheduleTransitions(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	var body schema.ScheduleSectionsBody
+	if err := c.ShouldBindJSON(&body); err != nil {
+		respond(c, http.StatusBadRequest, "Invalid request body", err.Error())
+		return
+	}
+
+	sections, err := findScheduleSections(ctx, c, body.Sections)
+	if err != nil {
+		return
+	}
+	matrix, err := findWalkingMatrix(ctx, c)
+	if err != nil {
+		return
+	}
+
+	respond(c, http.StatusOK, "success", scheduleTransitions(sections, matrix))
+}
+
+// findWalkingMatrix builds the walking matrix from the locations of the buildings.
+// Automatically responds with an error if they can't be retrieved.
+func findWalkingMatrix(ctx context.Context, c *gin.Context) (walkingMatrix, error) {
+	buildingRooms, err := findBuildingRooms(ctx, c, "")
+	if err != nil {
+		return walkingMatrix{}, err
+	}
+
+	matrix := walkingMatrix{coordinates: make(map[string][2]float64, len(buildingRooms))}
+	for _, building := range buildingRooms {
+		// Buildings without coordinates are stored at 0, 0
+		if building.Lat != 0 || building.Lng != 0 {
+			matrix.coordinates[strings.ToUpper(strings.TrimSpace(building.Building))] = [2]float64{building.Lat, building.Lng}
+		}
+	}
+	return matrix, nil
+}
+
+// walk estimates the minutes and meters it takes to walk between two buildings, ignoring case.
+// Either is nil if it can't be estimated, minutes can still be estimated for overridden walks.
+func (matrix walkingMatrix) walk(from string, to string) (*int, *float64) {
+	from, to = strings.ToUpper(strings.TrimSpace(from)), strings.ToUpper(strings.TrimSpace(to))
+
+	var distance *float64
+	fromCoordinates, fromKnown := matrix.coordinates[from]
+	toCoordinates, toKnown := matrix.coordinates[to]
+	if fromKnown && toKnown {
+		meters := math.Round(haversineDistance(fromCoordinates[0], fromCoordinates[1], toCoordinates[0], toCoordinates[1]))
+		distance = &meters
+	}
+
+	pair := [2]string{from, to}
+	if to < from {
+		pair = [2]string{to, from}
+	}
+	if minutes, ok := walkMinuteOverrides[pair]; ok {
+		return &minutes, distance
+	}
+	if from == to && from != "" {
+		minutes := 0
+		return &minutes, distance
+	}
+	if distance == nil {
+		return nil, nil
+	}
+	minutes := int(math.Ceil(*distance * routeFactor / walkingSpeed))
+	return &minutes, distance
+}
+
+// scheduleTransitions finds every meeting followed by one of a different section within the maximum gap on the same days,
+// and whether there's time to walk between them
+func scheduleTransitions(sections []schema.Section, matrix walkingMatrix) []schema.ScheduleTransition {
+	transitions := make([]schema.ScheduleTransition, 0)
+
+	var intervals []meetingInterval
+	for _, section := range sections {
+		intervals = append(intervals, meetingIntervals(section)...)
+	}
+
+	for _, a := range intervals {
+		for _, b := range intervals {
+			gap := b.start - a.end
+			if a.section == b.section || gap < 0 || gap > maxTransitionGap {
+				continue
+			}
+
+			startDate := laterDate(a.startDate, b.startDate)
+			endDate := earlierDate(a.endDate, b.endDate)
+			if !startDate.IsZero() && !endDate.IsZero() && dateOnly(startDate).After(dateOnly(endDate)) {
+				continue
+			}
+			shared := a.days & b.days & weekdaysBetween(startDate, endDate)
+
+			// Only transitions without another meeting in between count
+			for _, day := range shared.Days() {
+				if slices.ContainsFunc(intervals, func(other meetingInterval) bool {
+					return other.days.Has(day) && other.start >= a.end && other.end <= b.start
+				}) {
+					shared &^= schema.NewWeekdays(day)
+				}
+			}
+			if shared == 0 {
+				continue
+			}
+
+			var days []string
+			for _, day := range shared.Days() {
+				days = append(days, day.String())
+			}
+			walkMinutes, distance := matrix.walk(a.location.Building, b.location.Building)
+			transitions = append(transitions, schema.ScheduleTransition{
+				Sections:     [2]primitive.ObjectID{a.section, b.section},
+				Days:         days,
+				From:         a.location,
+				To:           b.location,
+				End_time:     schema.FormatClockTime(a.end),
+				Start_time:   schema.FormatClockTime(b.start),
+				Gap_minutes:  gap,
+				Walk_minutes: walkMinutes,
+				Distance:     distance,
+				Status:       transitionStatus(gap, walkMinutes),
+			})
+		}
+	}
+	return transitions
+}
+
+// transitionStatus rates whether there's time to walk from one meeting to the next
+func transitionStatus(gap int, walkMinutes *int) string {
+	switch {
+	case walkMinutes == nil:
+		return transitionUnknown
+	case *walkMinutes > gap:
+		return transitionInfeasible
+	case gap-*walkMinutes < tightTransitionMinutes:
+		return transitionTight
+	}
+	return transitionOK
+}
@@ -10,6 +10,7 @@ func AstraRoute(router *gin.Engine) {
 	astraGroup := router.Group("/astra")
 
 	astraGroup.OPTIONS("", controllers.Preflight)
+	astraGroup.GET("", controllers.AstraEventsRange)
 	astraGroup.GET(":date", controllers.AstraEvents)
 	astraGroup.GET(":date/:building", controllers.AstraEventsByBuilding)
 	astraGroup.GET(":date/:building/:room", controllers.AstraEventsByBuildingAndRoom)
@@ -10,5 +10,7 @@ func AutocompleteRoute(router *gin.Engine) {
 	// All routes related to autocomplete come here
 	autocompleteGroup := router.Group("/autocomplete")
 
+	autocompleteGroup.OPTIONS("", controllers.Preflight)
+	autocompleteGroup.GET("", controllers.Autocomplete)
 	autocompleteGroup.GET("/dag", controllers.AutocompleteDAG)
 }
@@ -0,0 +1,16 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/UTDNebula/nebula-api/api/controllers"
+)
+
+func BuildingsRoute(router *gin.Engine) {
+	// All routes related to the campus map buildings come here
+	buildingsGroup := router.Group("/buildings")
+
+	buildingsGroup.OPTIONS("", controllers.Preflight)
+	buildingsGroup.GET("", controllers.Buildings)
+	buildingsGroup.GET("walking", controllers.BuildingsWalking)
+}
@@ -10,6 +10,9 @@ func CalendarRoute(router *gin.Engine) {
 	calendarGroup := router.Group("/calendar")
 
 	calendarGroup.OPTIONS("", controllers.Preflight)
+	calendarGroup.GET("", controllers.CometCalendarEventsRange)
+	calendarGroup.GET("search", controllers.CometCalendarSearch)
+	calendarGroup.GET("feed.ics", controllers.CometCalendarFeed)
 	// More specific routes must be defined first in Gin
 	calendarGroup.GET(":date/:building/:room", controllers.CometCalendarEventsByBuildingAndRoom)
 	calendarGroup.GET(":date/:building", controllers.CometCalendarEventsByBuilding)
@@ -14,6 +14,7 @@ func CourseRoute(router *gin.Engine) {
 	courseGroup.GET("", controllers.CourseSearch)
 	courseGroup.GET(":id", controllers.CourseById)
 	courseGroup.GET("all", controllers.CourseAll)
+	courseGroup.GET("search", controllers.CourseFuzzySearch)
 
 	// Endpoint to get the list of sections of the queried courses
 	courseGroup.GET("/sections", controllers.CourseSectionSearch)
@@ -23,6 +24,12 @@ func CourseRoute(router *gin.Engine) {
 	// Endpoint to get grades for a course by its course id
 	courseGroup.GET("/:id/grades", controllers.GradesByCourseID)
 
+	// Endpoint to get the cross-listed, renumbered and aliased equivalents of a course
+	courseGroup.GET("/:id/equivalents", controllers.CourseEquivalents)
+
+	// Endpoint to get the courses most similar to a course
+	courseGroup.GET("/:id/similar", controllers.CourseSimilar)
+
 	// Endpoint to get the list of professors of the queried courses
 	courseGroup.GET("/professors", controllers.CourseProfessorSearch)
 	courseGroup.GET("/:id/professors", controllers.CourseProfessorById)
@@ -11,6 +11,7 @@ func EventsRoute(router *gin.Engine) {
 	eventsGroup := router.Group("/events")
 
 	eventsGroup.OPTIONS("", controllers.Preflight)
+	eventsGroup.GET("", controllers.EventsRange)
 	eventsGroup.GET(":date", controllers.Events)
 	eventsGroup.GET(":date/:building", controllers.EventsByBuilding)
 	eventsGroup.GET(":date/:building/:room", controllers.EventsByRoom)
@@ -19,4 +19,8 @@ func GradesRoute(router *gin.Engine) {
 	gradesGroup.GET("semester", controllers.GradeAggregationSemester)
 	gradesGroup.GET("semester/sectionType", controllers.GradesAggregationSectionType)
 	gradesGroup.GET("overall", controllers.GradesAggregationOverall)
+
+	// Endpoints to rank grades within a peer group
+	gradesGroup.GET("rankings/professors", controllers.ProfessorRankings)
+	gradesGroup.GET("rankings/courses", controllers.CourseRankings)
 }
@@ -10,5 +10,8 @@ func MazevoRoute(router *gin.Engine) {
 	mazevoGroup := router.Group("/mazevo")
 
 	mazevoGroup.OPTIONS("", controllers.Preflight)
+	mazevoGroup.GET("", controllers.MazevoEventsRange)
 	mazevoGroup.GET(":date", controllers.MazevoEvents)
+	mazevoGroup.GET(":date/:building", controllers.MazevoEventsByBuilding)
+	mazevoGroup.GET(":date/:building/:room", controllers.MazevoEventsByBuildingAndRoom)
 }
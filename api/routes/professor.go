@@ -14,6 +14,7 @@ func ProfessorRoute(router *gin.Engine) {
 	professorGroup.GET("", controllers.ProfessorSearch)
 	professorGroup.GET(":id", controllers.ProfessorById)
 	professorGroup.GET("all", controllers.ProfessorAll)
+	professorGroup.GET("search", controllers.ProfessorNameSearch)
 
 	// Endpoints to get the courses of the professors
 	professorGroup.GET("courses", controllers.ProfessorCourseSearch)
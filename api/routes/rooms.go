@@ -12,4 +12,8 @@ func RoomsRoute(router *gin.Engine) {
 
 	roomsGroup.OPTIONS("", controllers.Preflight)
 	roomsGroup.GET("", controllers.Rooms)
+	roomsGroup.GET("available", controllers.RoomsAvailable)
+	roomsGroup.GET("utilization", controllers.RoomsUtilization)
+	roomsGroup.GET("near", controllers.RoomsNear)
+	roomsGroup.GET(":building/:room/schedule", controllers.RoomWeekSchedule)
 }
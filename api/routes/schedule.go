@@ -0,0 +1,17 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/UTDNebula/nebula-api/api/controllers"
+)
+
+func ScheduleRoute(router *gin.Engine) {
+	// All routes related to building schedules come here
+	scheduleGroup := router.Group("/schedule")
+
+	scheduleGroup.OPTIONS("", controllers.Preflight)
+	scheduleGroup.POST("conflicts", controllers.ScheduleConflicts)
+	scheduleGroup.POST("generate", controllers.ScheduleGenerate)
+	scheduleGroup.POST("transitions", controllers.ScheduleTransitions)
+}
@@ -0,0 +1,15 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/UTDNebula/nebula-api/api/controllers"
+)
+
+func SearchRoute(router *gin.Engine) {
+	// All routes related to searching every type of object at once come here
+	searchGroup := router.Group("/search")
+
+	searchGroup.OPTIONS("", controllers.Preflight)
+	searchGroup.GET("", controllers.Search)
+}
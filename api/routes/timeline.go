@@ -0,0 +1,17 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/UTDNebula/nebula-api/api/controllers"
+)
+
+func TimelineRoute(router *gin.Engine) {
+	// All routes related to the merged events of every source come here
+	timelineGroup := router.Group("/timeline")
+
+	timelineGroup.OPTIONS("", controllers.Preflight)
+	timelineGroup.GET(":date", controllers.Timeline)
+	timelineGroup.GET(":date/:building", controllers.TimelineByBuilding)
+	timelineGroup.GET(":date/:building/:room", controllers.TimelineByRoom)
+}
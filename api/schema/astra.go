@@ -0,0 +1,164 @@
+package schema
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// AstraState is the status of an Astra booking, normalized from the many state names Astra uses
+type AstraState string
+
+const (
+	AstraStateConfirmed AstraState = "confirmed"
+	AstraStatePending   AstraState = "pending"
+	AstraStateCancelled AstraState = "cancelled"
+	AstraStateDenied    AstraState = "denied"
+	AstraStateUnknown   AstraState = "unknown"
+)
+
+// Astra doesn't document its state names or what each bit of a usage mask means, so beyond the normalized state names
+// both are configured per deployment with SetAstraStateNames and SetAstraUsageNames
+
+// States by the lowercase Astra state names that mean them
+var astraStateNames = map[string]AstraState{
+	string(AstraStateConfirmed): AstraStateConfirmed,
+	string(AstraStatePending):   AstraStatePending,
+	string(AstraStateCancelled): AstraStateCancelled,
+	string(AstraStateDenied):    AstraStateDenied,
+}
+
+// States some configured Astra state name means, which are the only ones events can be filtered by.
+// Unknown is what every other name means.
+var astraConfiguredStates = map[AstraState]bool{AstraStateUnknown: true}
+
+// Usages of a space by the bit of NotAllowedUsageMask that disallows them, empty for bits without a configured name
+var astraUsages []string
+
+// SetAstraStateNames maps Astra state names to the states they mean, e.g. "Scheduled" to "confirmed".
+// It must be called before events are decoded, at startup.
+func SetAstraStateNames(names map[string]string) error {
+	for name, value := range names {
+		state, err := parseAstraStateName(value)
+		if err != nil {
+			return fmt.Errorf("invalid state '%s' for the Astra state name '%s'", value, name)
+		}
+		astraStateNames[strings.ToLower(strings.TrimSpace(name))] = state
+		astraConfiguredStates[state] = true
+	}
+	return nil
+}
+
+// SetAstraUsageNames names the bits of NotAllowedUsageMask in order, starting from the lowest bit.
+// It must be called before events are decoded, at startup.
+func SetAstraUsageNames(names []string) {
+	astraUsages = make([]string, len(names))
+	for i, name := range names {
+		astraUsages[i] = strings.TrimSpace(name)
+	}
+}
+
+// ParseAstraState maps an Astra state name to its state, ignoring case.
+// Names which aren't known are AstraStateUnknown.
+func ParseAstraState(value string) AstraState {
+	if state, ok := astraStateNames[strings.ToLower(strings.TrimSpace(value))]; ok {
+		return state
+	}
+	return AstraStateUnknown
+}
+
+// ParseAstraStates parses a comma-separated list of states to filter events by, such as "confirmed,pending".
+// States no configured Astra state name means are rejected, since filtering by them would never match anything.
+func ParseAstraStates(value string) ([]AstraState, error) {
+	var states []AstraState
+	for _, name := range strings.Split(value, ",") {
+		state, err := parseAstraStateName(name)
+		if err != nil {
+			return nil, err
+		}
+		if !astraConfiguredStates[state] {
+			return nil, fmt.Errorf("state '%s' can't be filtered by, no Astra state names are configured to mean it", state)
+		}
+		states = append(states, state)
+	}
+	return states, nil
+}
+
+// parseAstraStateName parses the name of a state such as "confirmed", ignoring case
+func parseAstraStateName(name string) (AstraState, error) {
+	state := AstraState(strings.ToLower(strings.TrimSpace(name)))
+	switch state {
+	case AstraStateConfirmed, AstraStatePending, AstraStateCancelled, AstraStateDenied, AstraStateUnknown:
+		return state, nil
+	}
+	return "", fmt.Errorf("invalid state '%s', expected confirmed, pending, cancelled, denied or unknown", name)
+}
+
+// DecodeAstraUsageMask lists the usages disallowed by a NotAllowedUsageMask, in bit order.
+// Bits without a configured name are listed as "usage_<bit>" so that none are lost.
+func DecodeAstraUsageMask(mask float64) []string {
+	usages := make([]string, 0)
+	if mask <= 0 || mask > math.MaxInt32 {
+		return usages
+	}
+	bits := uint32(mask)
+	for bit := range 32 {
+		if bits&(1<<bit) == 0 {
+			continue
+		}
+		if bit < len(astraUsages) && astraUsages[bit] != "" {
+			usages = append(usages, astraUsages[bit])
+		} else {
+			usages = append(usages, fmt.Sprintf("usage_%d", bit))
+		}
+	}
+	return usages
+}
+
+// ParseAstraColor normalizes a hex color such as "#F80", "ff8800" or "#FF8800FF" to "#ff8800", ignoring any alpha.
+// Returns false if the color isn't in hex.
+func ParseAstraColor(value string) (string, bool) {
+	hex := strings.ToLower(strings.TrimPrefix(strings.TrimSpace(value), "#"))
+	if strings.Trim(hex, "0123456789abcdef") != "" {
+		return "", false
+	}
+	switch len(hex) {
+	case 3:
+		return "#" + string([]byte{hex[0], hex[0], hex[1], hex[1], hex[2], hex[2]}), true
+	case 6, 8:
+		return "#" + hex[:6], true
+	}
+	return "", false
+}
+
+// Astra events are decoded as stored, then their decoded fields are filled in
+type storedAstraEvent AstraEvent
+
+func (event *AstraEvent) UnmarshalBSON(data []byte) error {
+	if err := bson.Unmarshal(data, (*storedAstraEvent)(event)); err != nil {
+		return err
+	}
+	event.Decode()
+	return nil
+}
+
+// Decode fills in the fields of the event decoded from its state, usage mask and usage color.
+// This happens automatically when an event is decoded from the database.
+func (event *AstraEvent) Decode() {
+	event.State = AstraStateUnknown
+	if event.CurrentState != nil {
+		event.State = ParseAstraState(*event.CurrentState)
+	}
+	event.DisallowedUsages = make([]string, 0)
+	if event.NotAllowedUsageMask != nil {
+		event.DisallowedUsages = DecodeAstraUsageMask(*event.NotAllowedUsageMask)
+	}
+	event.Color = nil
+	if event.UsageColor != nil {
+		if color, ok := ParseAstraColor(*event.UsageColor); ok {
+			event.Color = &color
+		}
+	}
+}
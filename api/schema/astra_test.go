@@ -0,0 +1,135 @@
+package schema
+
+import (
+	"slices"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// configureAstra names the Astra states and usages for a test, restoring the defaults afterwards
+func configureAstra(t *testing.T, states map[string]string, usages []string) {
+	t.Helper()
+	stateNames := make(map[string]AstraState, len(astraStateNames))
+	for name, state := range astraStateNames {
+		stateNames[name] = state
+	}
+	configuredStates := make(map[AstraState]bool, len(astraConfiguredStates))
+	for state := range astraConfiguredStates {
+		configuredStates[state] = true
+	}
+	previousUsages := astraUsages
+	t.Cleanup(func() {
+		astraStateNames, astraConfiguredStates, astraUsages = stateNames, configuredStates, previousUsages
+	})
+
+	if err := SetAstraStateNames(states); err != nil {
+		t.Fatal(err)
+	}
+	SetAstraUsageNames(usages)
+}
+
+func TestDecodeAstraUsageMask(t *testing.T) {
+	testCases := map[string]struct {
+		Mask     float64
+		Expected []string
+	}{
+		"None":     {Mask: 0, Expected: []string{}},
+		"Single":   {Mask: 4, Expected: []string{"usage_2"}},
+		"Multiple": {Mask: 11, Expected: []string{"usage_0", "usage_1", "usage_3"}},
+		"Negative": {Mask: -1, Expected: []string{}},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			if result := DecodeAstraUsageMask(tc.Mask); !slices.Equal(result, tc.Expected) {
+				t.Errorf("Expected %v, got %v", tc.Expected, result)
+			}
+		})
+	}
+
+	t.Run("Configured", func(t *testing.T) {
+		configureAstra(t, nil, []string{"first", "", "third"})
+		if result := DecodeAstraUsageMask(1<<10 | 7); !slices.Equal(result, []string{"first", "usage_1", "third", "usage_10"}) {
+			t.Errorf("Expected the configured names, got %v", result)
+		}
+	})
+}
+
+func TestAstraEventDecodedOnUnmarshal(t *testing.T) {
+	configureAstra(t, map[string]string{"Scheduled": "confirmed"}, []string{"first", "", "third"})
+
+	data, err := bson.Marshal(bson.M{"events": bson.A{
+		bson.M{"activity_name": "Career Fair", "current_state": "Scheduled", "not_allowed_usage_mask": 5.0, "usage_color": "#F80"},
+		bson.M{"activity_name": "Review Session", "current_state": "Cancelled", "usage_color": "red"},
+		bson.M{"activity_name": "Open House", "current_state": "Tentative"},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var room RoomEvents[AstraEvent]
+	if err = bson.Unmarshal(data, &room); err != nil {
+		t.Fatal(err)
+	}
+
+	if event := room.Events[0]; event.State != AstraStateConfirmed || !slices.Equal(event.DisallowedUsages, []string{"first", "third"}) ||
+		event.Color == nil || *event.Color != "#ff8800" {
+		t.Errorf("Expected a confirmed booking disallowing the first and third usages colored #ff8800, got %s %v %v", event.State, event.DisallowedUsages, event.Color)
+	}
+	if event := room.Events[1]; event.State != AstraStateCancelled || len(event.DisallowedUsages) != 0 || event.Color != nil {
+		t.Errorf("Expected a cancelled booking without disallowed usages or color, got %s %v %v", event.State, event.DisallowedUsages, event.Color)
+	}
+	// State names which aren't configured are unknown, their raw state is still available
+	if event := room.Events[2]; event.State != AstraStateUnknown || *event.CurrentState != "Tentative" {
+		t.Errorf("Expected an unknown state, got %s", event.State)
+	}
+
+	if err := SetAstraStateNames(map[string]string{"Booked": "booked"}); err == nil {
+		t.Errorf("Expected an error for a state name mapped to an invalid state")
+	}
+}
+
+func TestParseAstraColor(t *testing.T) {
+	testCases := map[string]struct {
+		Value    string
+		Expected string
+		Invalid  bool
+	}{
+		"Short":   {Value: "#F80", Expected: "#ff8800"},
+		"Long":    {Value: "FF8800", Expected: "#ff8800"},
+		"Alpha":   {Value: " #ff8800cc ", Expected: "#ff8800"},
+		"Name":    {Value: "red", Invalid: true},
+		"Length":  {Value: "#ff88", Invalid: true},
+		"Nothing": {Value: "", Invalid: true},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			color, ok := ParseAstraColor(tc.Value)
+			if ok == tc.Invalid || color != tc.Expected {
+				t.Errorf("Expected %q (valid %t), got %q (valid %t)", tc.Expected, !tc.Invalid, color, ok)
+			}
+		})
+	}
+}
+
+func TestParseAstraStates(t *testing.T) {
+	if _, err := ParseAstraStates("confirmed"); err == nil {
+		t.Error("Expected filtering by a state no name is configured to mean to be rejected")
+	}
+	if states, err := ParseAstraStates("unknown"); err != nil || !slices.Equal(states, []AstraState{AstraStateUnknown}) {
+		t.Errorf("Expected unknown to always be allowed, got %v, %v", states, err)
+	}
+
+	configureAstra(t, map[string]string{"Scheduled": "confirmed", "Tentative": "pending"}, nil)
+	if states, err := ParseAstraStates(" Confirmed,pending"); err != nil || !slices.Equal(states, []AstraState{AstraStateConfirmed, AstraStatePending}) {
+		t.Errorf("Expected confirmed and pending, got %v, %v", states, err)
+	}
+	if _, err := ParseAstraStates("confirmed,cancelled"); err == nil {
+		t.Error("Expected cancelled to be rejected while no name is configured to mean it")
+	}
+	if _, err := ParseAstraStates("scheduled"); err == nil {
+		t.Error("Expected Astra state names not to be accepted as states")
+	}
+}
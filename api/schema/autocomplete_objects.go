@@ -30,3 +30,10 @@ type SimpleProfessor struct {
 	First_name string `bson:"first_name" json:"first_name" schema:"first_name"`
 	Last_name  string `bson:"last_name" json:"last_name" schema:"last_name"`
 }
+
+// A completion of a search string from the autocomplete index
+type AutocompleteSuggestion struct {
+	Type  string `json:"type"`  // course, title or professor
+	Value string `json:"value"` // the completed text, such as "CS 3345", a course title or "Jane Doe"
+	Label string `json:"label"` // the value along with what it refers to, such as "CS 3345 Data Structures and Introduction to Algorithmic Analysis"
+}
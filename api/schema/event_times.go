@@ -0,0 +1,37 @@
+package schema
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	_ "time/tzdata" // the distroless image has no zoneinfo
+)
+
+// Campus time, event times stored without a zone are in it
+var CampusLocation = func() *time.Location {
+	location, err := time.LoadLocation("America/Chicago")
+	if err != nil {
+		panic(err)
+	}
+	return location
+}()
+
+// Layouts of the timestamps stored by the event sources, most specific first
+var eventTimestampLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02T15:04",
+	"2006-01-02 15:04",
+}
+
+// ParseEventTimestamp parses a timestamp stored by any of the event sources, in campus time unless it has a zone
+func ParseEventTimestamp(value string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	for _, layout := range eventTimestampLayouts {
+		if parsed, err := time.ParseInLocation(layout, value, CampusLocation); err == nil {
+			return parsed, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid event timestamp '%s'", value)
+}
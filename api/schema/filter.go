@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"net/url"
 	"reflect"
+	"regexp"
+	"slices"
 	"strings"
 	"sync"
 	"time"
@@ -21,8 +23,17 @@ var (
 	ignoredParameters = map[string]bool{
 		"offset": true,
 	}
+	// Matches a query parameter with a comparison operator, e.g. meetings.start_minutes[gte]
+	operatorRegex = regexp.MustCompile(`^(.+)\[([a-z]+)\]$`)
 )
 
+// derivedQueryable is implemented by types with queryable fields which are computed on decode instead of stored.
+// derivedFilter returns an aggregation expression over the stored fields of element (e.g. "$$element")
+// which is true when the computed field matches the operator and value.
+type derivedQueryable interface {
+	derivedFilter(element string, field string, operator string, value string) (bson.M, error)
+}
+
 // FilterQuery converts URL query parameters into a MongoDB BSON query filter.
 //
 // It validates that each query parameter corresponds to a field in type F that is
@@ -38,12 +49,18 @@ func FilterQuery[F any](urlValues url.Values) (bson.M, error) {
 	}
 
 	query := bson.M{}
+	derivedFilters := make(map[string]bson.A)
 	for key, values := range urlValues {
 		if _, ok := ignoredParameters[key]; ok {
 			continue
 		}
 
-		allowed, exists := queryable[key]
+		field, operator := key, ""
+		if match := operatorRegex.FindStringSubmatch(key); match != nil {
+			field, operator = match[1], match[2]
+		}
+
+		allowed, exists := queryable[field]
 		if !exists {
 			return nil, fmt.Errorf("unknown query parameter '%s'", key)
 		}
@@ -51,16 +68,110 @@ func FilterQuery[F any](urlValues url.Values) (bson.M, error) {
 			return nil, fmt.Errorf("field '%s' cannot be used for filtering", key)
 		}
 
-		if len(values) == 0 {
-			query[key] = ""
-		} else {
-			query[key] = values[0]
+		value := ""
+		if len(values) != 0 {
+			value = values[0]
+		}
+
+		// Computed fields are matched by computing them from the stored fields of their parent
+		if parent, parentPath, name, ok := derivedField(reflect.TypeFor[F](), field); ok {
+			if operator == "" {
+				operator = "eq"
+			}
+			filter, err := parent.derivedFilter("$$element", name, operator, value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid value for '%s': %w", key, err)
+			}
+			derivedFilters[parentPath] = append(derivedFilters[parentPath], filter)
+			continue
 		}
+		if operator != "" {
+			return nil, fmt.Errorf("field '%s' does not support operators", field)
+		}
+
+		query[key] = value
+	}
+
+	if len(derivedFilters) > 0 {
+		query["$expr"] = derivedExpression(derivedFilters)
 	}
 
 	return query, nil
 }
 
+// derivedField finds the computed field at the JSON path in type t, along with its parent and the parent's path.
+// Returns false if the path isn't a field marked as derived.
+func derivedField(t reflect.Type, path string) (derivedQueryable, string, string, bool) {
+	segments := strings.Split(path, ".")
+	for i, segment := range segments {
+		t = drillType(t)
+		if t.Kind() != reflect.Struct {
+			return nil, "", "", false
+		}
+		field, ok := jsonField(t, segment)
+		if !ok {
+			return nil, "", "", false
+		}
+		if i < len(segments)-1 {
+			t = field.Type
+			continue
+		}
+
+		if field.Tag.Get("queryable") != "derived" {
+			return nil, "", "", false
+		}
+		parent, ok := reflect.New(t).Interface().(derivedQueryable)
+		return parent, strings.Join(segments[:i], "."), segment, ok
+	}
+	return nil, "", "", false
+}
+
+// jsonField finds the field of struct type t with the given JSON name, looking into inlined fields
+func jsonField(t reflect.Type, name string) (reflect.StructField, bool) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		json := strings.Split(field.Tag.Get("json"), ",")[0]
+		if json == name {
+			return field, true
+		}
+		if json == "" && drillType(field.Type).Kind() == reflect.Struct {
+			if inlined, ok := jsonField(drillType(field.Type), name); ok {
+				return inlined, true
+			}
+		}
+	}
+	return reflect.StructField{}, false
+}
+
+// derivedExpression combines the filters on computed fields into a single $expr.
+// Filters sharing a parent must all match the same element of it, e.g. the same meeting of a section.
+func derivedExpression(derivedFilters map[string]bson.A) bson.M {
+	parentPaths := make([]string, 0, len(derivedFilters))
+	for parentPath := range derivedFilters {
+		parentPaths = append(parentPaths, parentPath)
+	}
+	slices.Sort(parentPaths)
+
+	expressions := make(bson.A, 0, len(parentPaths))
+	for _, parentPath := range parentPaths {
+		var input any = bson.A{"$$ROOT"}
+		if parentPath != "" {
+			// Parents which aren't arrays are treated as an array of one element
+			input = bson.M{"$cond": bson.A{bson.M{"$isArray": "$" + parentPath}, "$" + parentPath, bson.A{"$" + parentPath}}}
+		}
+		expressions = append(expressions, bson.M{"$anyElementTrue": bson.A{bson.M{"$map": bson.M{
+			"input": input,
+			"as":    "element",
+			"in":    bson.M{"$and": derivedFilters[parentPath]},
+		}}}})
+	}
+
+	if len(expressions) == 1 {
+		return expressions[0].(bson.M)
+	}
+	return bson.M{"$and": expressions}
+}
+
 // loadQueryable returns a map indicating which fields of the given type are queryable.
 func loadQueryable(t reflect.Type) (map[string]bool, error) {
 	if cached, ok := queryableCache.Load(t); ok {
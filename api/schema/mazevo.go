@@ -0,0 +1,50 @@
+package schema
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// Mazevo events are decoded as stored, then their computed fields are filled in
+type storedMazevoEvent MazevoEvent
+
+func (event *MazevoEvent) UnmarshalBSON(data []byte) error {
+	if err := bson.Unmarshal(data, (*storedMazevoEvent)(event)); err != nil {
+		return err
+	}
+	event.ComputeTimes()
+	return nil
+}
+
+// ComputeTimes fills in the fields of the event parsed from its timestamps, setup and teardown.
+// The occupancy window is left empty unless the event ends after it starts.
+// This happens automatically when an event is decoded from the database.
+func (event *MazevoEvent) ComputeTimes() {
+	event.Start, event.End, event.OccupiedStart, event.OccupiedEnd = nil, nil, nil, nil
+	if event.DateTimeStart != nil {
+		if start, err := ParseEventTimestamp(*event.DateTimeStart); err == nil {
+			event.Start = &start
+		}
+	}
+	if event.DateTimeEnd != nil {
+		if end, err := ParseEventTimestamp(*event.DateTimeEnd); err == nil {
+			event.End = &end
+		}
+	}
+	if event.Start == nil || event.End == nil || !event.End.After(*event.Start) {
+		return
+	}
+
+	occupiedStart := event.Start.Add(-minutesDuration(event.SetupMinutes))
+	occupiedEnd := event.End.Add(minutesDuration(event.TeardownMinutes))
+	event.OccupiedStart, event.OccupiedEnd = &occupiedStart, &occupiedEnd
+}
+
+// minutesDuration converts optional minutes to a duration, treating missing and negative minutes as none
+func minutesDuration(minutes *float64) time.Duration {
+	if minutes == nil || *minutes <= 0 {
+		return 0
+	}
+	return time.Duration(*minutes * float64(time.Minute))
+}
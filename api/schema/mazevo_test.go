@@ -0,0 +1,44 @@
+package schema
+
+import (
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestMazevoEventComputedOnDecode(t *testing.T) {
+	data, err := bson.Marshal(bson.M{"events": bson.A{
+		bson.M{"eventName": "Gala", "dateTimeStart": "2025-09-02T18:00:00", "dateTimeEnd": "2025-09-02T21:00:00", "setupMinutes": 45.0, "teardownMinutes": 30.0},
+		bson.M{"eventName": "Meeting", "dateTimeStart": "2025-09-02T23:00:00Z", "dateTimeEnd": "2025-09-03T00:00:00Z"},
+		bson.M{"eventName": "Unscheduled", "dateTimeStart": "TBD"},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var room RoomEvents[MazevoEvent]
+	if err = bson.Unmarshal(data, &room); err != nil {
+		t.Fatal(err)
+	}
+
+	at := func(hour int, minute int) time.Time {
+		return time.Date(2025, 9, 2, hour, minute, 0, 0, CampusLocation)
+	}
+	gala := room.Events[0]
+	if gala.Start == nil || !gala.Start.Equal(at(18, 0)) || gala.End == nil || !gala.End.Equal(at(21, 0)) {
+		t.Errorf("Expected the gala from 18:00 to 21:00, got %v to %v", gala.Start, gala.End)
+	}
+	if gala.OccupiedStart == nil || !gala.OccupiedStart.Equal(at(17, 15)) || gala.OccupiedEnd == nil || !gala.OccupiedEnd.Equal(at(21, 30)) {
+		t.Errorf("Expected the gala to occupy its room from 17:15 to 21:30, got %v to %v", gala.OccupiedStart, gala.OccupiedEnd)
+	}
+
+	meeting := room.Events[1]
+	if meeting.OccupiedStart == nil || !meeting.OccupiedStart.Equal(at(18, 0)) || !meeting.OccupiedEnd.Equal(*meeting.End) {
+		t.Errorf("Expected the meeting to occupy its room from 18:00 without setup, got %v to %v", meeting.OccupiedStart, meeting.OccupiedEnd)
+	}
+
+	if unscheduled := room.Events[2]; unscheduled.Start != nil || unscheduled.OccupiedStart != nil {
+		t.Errorf("Expected no computed times for an unscheduled event, got %+v", unscheduled)
+	}
+}
@@ -0,0 +1,311 @@
+package schema
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// ParseClockTime parses a time of day such as "10:00am", "2:30 PM", "14:30" or "9am" into minutes since midnight
+func ParseClockTime(value string) (int, error) {
+	clock := strings.ToLower(strings.ReplaceAll(strings.TrimSpace(value), " ", ""))
+	clock = strings.ReplaceAll(clock, ".", "")
+
+	meridiem := ""
+	if trimmed, ok := strings.CutSuffix(clock, "am"); ok {
+		clock, meridiem = trimmed, "am"
+	} else if trimmed, ok := strings.CutSuffix(clock, "pm"); ok {
+		clock, meridiem = trimmed, "pm"
+	}
+
+	hourString, minuteString, hasMinutes := strings.Cut(clock, ":")
+	if !hasMinutes {
+		minuteString = "0"
+	}
+	hour, err := strconv.Atoi(hourString)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time of day '%s'", value)
+	}
+	minute, err := strconv.Atoi(minuteString)
+	if err != nil || minute < 0 || minute > 59 || (hasMinutes && len(minuteString) != 2) {
+		return 0, fmt.Errorf("invalid time of day '%s'", value)
+	}
+
+	switch meridiem {
+	case "am", "pm":
+		if hour < 1 || hour > 12 {
+			return 0, fmt.Errorf("invalid time of day '%s'", value)
+		}
+		hour %= 12
+		if meridiem == "pm" {
+			hour += 12
+		}
+	default:
+		// A 24 hour time needs its minutes to be unambiguous
+		if !hasMinutes || hour < 0 || hour > 24 || (hour == 24 && minute != 0) {
+			return 0, fmt.Errorf("invalid time of day '%s'", value)
+		}
+	}
+
+	return hour*60 + minute, nil
+}
+
+// FormatClockTime formats minutes since midnight as a 24 hour time, e.g. "14:30"
+func FormatClockTime(minutes int) string {
+	return fmt.Sprintf("%02d:%02d", minutes/60, minutes%60)
+}
+
+var weekdayNames = map[string]time.Weekday{
+	"sunday":    time.Sunday,
+	"sun":       time.Sunday,
+	"monday":    time.Monday,
+	"mon":       time.Monday,
+	"tuesday":   time.Tuesday,
+	"tue":       time.Tuesday,
+	"tues":      time.Tuesday,
+	"wednesday": time.Wednesday,
+	"wed":       time.Wednesday,
+	"thursday":  time.Thursday,
+	"thu":       time.Thursday,
+	"thur":      time.Thursday,
+	"thurs":     time.Thursday,
+	"friday":    time.Friday,
+	"fri":       time.Friday,
+	"saturday":  time.Saturday,
+	"sat":       time.Saturday,
+}
+
+// ParseWeekday parses a day name such as "Monday" or "Mon", ignoring case
+func ParseWeekday(value string) (time.Weekday, bool) {
+	weekday, ok := weekdayNames[strings.ToLower(strings.TrimSpace(value))]
+	return weekday, ok
+}
+
+// Weekdays is a set of days of the week, bit n is set when time.Weekday(n) is in the set
+type Weekdays uint8
+
+// Letters of the days of the week as abbreviated in course schedules, e.g. "TR" for Tuesday and Thursday
+const weekdayLetters = "UMTWRFS"
+
+// NewWeekdays returns the set of the given days
+func NewWeekdays(days ...time.Weekday) Weekdays {
+	var weekdays Weekdays
+	for _, day := range days {
+		weekdays |= 1 << day
+	}
+	return weekdays
+}
+
+// ParseWeekdays parses a set of days given as schedule letters ("TR"), comma separated names ("Tuesday,Thursday") or a bitset ("20")
+func ParseWeekdays(value string) (Weekdays, error) {
+	value = strings.TrimSpace(value)
+	if bits, err := strconv.ParseUint(value, 10, 8); err == nil {
+		if bits >= 1<<7 {
+			return 0, fmt.Errorf("invalid days '%s'", value)
+		}
+		return Weekdays(bits), nil
+	}
+
+	var weekdays Weekdays
+	if strings.Contains(value, ",") || len(value) > len(weekdayLetters) {
+		for _, name := range strings.Split(value, ",") {
+			day, ok := ParseWeekday(name)
+			if !ok {
+				return 0, fmt.Errorf("invalid days '%s'", value)
+			}
+			weekdays |= NewWeekdays(day)
+		}
+		return weekdays, nil
+	}
+
+	if day, ok := ParseWeekday(value); ok {
+		return NewWeekdays(day), nil
+	}
+	for _, letter := range strings.ToUpper(value) {
+		index := strings.IndexRune(weekdayLetters, letter)
+		if index < 0 {
+			return 0, fmt.Errorf("invalid days '%s'", value)
+		}
+		weekdays |= NewWeekdays(time.Weekday(index))
+	}
+	return weekdays, nil
+}
+
+// Has determines whether the day is in the set
+func (weekdays Weekdays) Has(day time.Weekday) bool {
+	return weekdays&(1<<day) != 0
+}
+
+// Days returns the days in the set from Sunday to Saturday
+func (weekdays Weekdays) Days() []time.Weekday {
+	var days []time.Weekday
+	for day := time.Sunday; day <= time.Saturday; day++ {
+		if weekdays.Has(day) {
+			days = append(days, day)
+		}
+	}
+	return days
+}
+
+// String formats the set as schedule letters, e.g. "MWF"
+func (weekdays Weekdays) String() string {
+	var letters strings.Builder
+	for _, day := range weekdays.Days() {
+		letters.WriteByte(weekdayLetters[day])
+	}
+	return letters.String()
+}
+
+// Meetings are decoded as stored, then their computed fields are filled in
+type storedMeeting Meeting
+
+func (meeting *Meeting) UnmarshalBSON(data []byte) error {
+	if err := bson.Unmarshal(data, (*storedMeeting)(meeting)); err != nil {
+		return err
+	}
+	meeting.ComputeTimes()
+	return nil
+}
+
+// ComputeTimes fills in the fields of the meeting parsed from its times and days.
+// This happens automatically when a meeting is decoded from the database.
+func (meeting *Meeting) ComputeTimes() {
+	meeting.Start_minutes, meeting.Start_time_24h = nil, ""
+	if minutes, err := ParseClockTime(meeting.Start_time); err == nil {
+		meeting.Start_minutes, meeting.Start_time_24h = &minutes, FormatClockTime(minutes)
+	}
+	meeting.End_minutes, meeting.End_time_24h = nil, ""
+	if minutes, err := ParseClockTime(meeting.End_time); err == nil {
+		meeting.End_minutes, meeting.End_time_24h = &minutes, FormatClockTime(minutes)
+	}
+
+	meeting.Days = 0
+	for _, name := range meeting.Meeting_days {
+		if day, ok := ParseWeekday(name); ok {
+			meeting.Days |= NewWeekdays(day)
+		}
+	}
+}
+
+// derivedFilter matches the computed fields of a meeting, which aren't stored, by computing them from the stored fields
+func (Meeting) derivedFilter(element string, field string, operator string, value string) (bson.M, error) {
+	switch field {
+	case "start_minutes", "start_time_24h":
+		return clockTimeFilter(element+".start_time", operator, value)
+	case "end_minutes", "end_time_24h":
+		return clockTimeFilter(element+".end_time", operator, value)
+	case "days":
+		return weekdaysFilter(element+".meeting_days", operator, value)
+	}
+	return nil, fmt.Errorf("field '%s' cannot be used for filtering", field)
+}
+
+// Comparison operators for times of day
+var clockTimeOperators = map[string]string{
+	"eq":  "$eq",
+	"ne":  "$ne",
+	"gt":  "$gt",
+	"gte": "$gte",
+	"lt":  "$lt",
+	"lte": "$lte",
+}
+
+// clockTimeFilter compares the time of day stored as text at an expression path (e.g. "$$element.start_time")
+// against a time given either as a clock time or minutes since midnight
+func clockTimeFilter(path string, operator string, value string) (bson.M, error) {
+	comparison, ok := clockTimeOperators[operator]
+	if !ok {
+		return nil, fmt.Errorf("invalid operator '%s' for a time, expected one of eq, ne, gt, gte, lt or lte", operator)
+	}
+	minutes, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil {
+		if minutes, err = ParseClockTime(value); err != nil {
+			return nil, err
+		}
+	}
+
+	// Meetings without a parseable time never match
+	return bson.M{"$let": bson.M{
+		"vars": bson.M{"minutes": clockTimeExpression(path)},
+		"in": bson.M{"$and": bson.A{
+			bson.M{"$ne": bson.A{"$$minutes", nil}},
+			bson.M{comparison: bson.A{"$$minutes", minutes}},
+		}},
+	}}, nil
+}
+
+// clockTimeExpression converts the text of a time at an expression path such as "2:30pm" or "14:30" into minutes since midnight,
+// or null if it can't be parsed. This mirrors ParseClockTime within an aggregation expression.
+func clockTimeExpression(path string) bson.M {
+	toInt := func(input any) bson.M {
+		return bson.M{"$convert": bson.M{"input": input, "to": "int", "onError": nil, "onNull": nil}}
+	}
+	return bson.M{"$let": bson.M{
+		"vars": bson.M{"time": bson.M{"$toLower": bson.M{"$trim": bson.M{"input": bson.M{"$ifNull": bson.A{path, ""}}}}}},
+		"in": bson.M{"$let": bson.M{
+			"vars": bson.M{
+				"parts":    bson.M{"$split": bson.A{"$$time", ":"}},
+				"meridiem": bson.M{"$regexMatch": bson.M{"input": "$$time", "regex": `[ap]\.?m\.?$`}},
+				"pm":       bson.M{"$regexMatch": bson.M{"input": "$$time", "regex": `p\.?m\.?$`}},
+			},
+			"in": bson.M{"$let": bson.M{
+				"vars": bson.M{
+					"hour": toInt(bson.M{"$trim": bson.M{"input": bson.M{"$arrayElemAt": bson.A{"$$parts", 0}}, "chars": " apm."}}),
+					"minute": bson.M{"$cond": bson.A{
+						bson.M{"$gt": bson.A{bson.M{"$size": "$$parts"}, 1}},
+						toInt(bson.M{"$substrCP": bson.A{bson.M{"$arrayElemAt": bson.A{"$$parts", 1}}, 0, 2}}),
+						0,
+					}},
+				},
+				"in": bson.M{"$cond": bson.A{
+					bson.M{"$or": bson.A{bson.M{"$eq": bson.A{"$$hour", nil}}, bson.M{"$eq": bson.A{"$$minute", nil}}}},
+					nil,
+					bson.M{"$add": bson.A{
+						bson.M{"$multiply": bson.A{
+							bson.M{"$cond": bson.A{
+								"$$meridiem",
+								bson.M{"$add": bson.A{bson.M{"$mod": bson.A{"$$hour", 12}}, bson.M{"$cond": bson.A{"$$pm", 12, 0}}}},
+								"$$hour",
+							}},
+							60,
+						}},
+						"$$minute",
+					}},
+				}},
+			}},
+		}},
+	}}
+}
+
+// weekdaysFilter compares the day names stored at an expression path (e.g. "$$element.meeting_days") against a set of days.
+// The eq operator matches exactly those days, all matches at least those days and any matches at least one of them.
+func weekdaysFilter(path string, operator string, value string) (bson.M, error) {
+	weekdays, err := ParseWeekdays(value)
+	if err != nil {
+		return nil, err
+	}
+	names := bson.A{}
+	for _, day := range weekdays.Days() {
+		names = append(names, strings.ToLower(day.String()))
+	}
+
+	// Day names are stored capitalized, e.g. "Monday"
+	stored := bson.M{"$setUnion": bson.A{bson.M{"$map": bson.M{
+		"input": bson.M{"$ifNull": bson.A{path, bson.A{}}},
+		"as":    "day",
+		"in":    bson.M{"$toLower": "$$day"},
+	}}}}
+
+	switch operator {
+	case "eq":
+		return bson.M{"$setEquals": bson.A{stored, names}}, nil
+	case "all":
+		return bson.M{"$setIsSubset": bson.A{names, stored}}, nil
+	case "any":
+		return bson.M{"$gt": bson.A{bson.M{"$size": bson.M{"$setIntersection": bson.A{stored, names}}}, 0}}, nil
+	}
+	return nil, fmt.Errorf("invalid operator '%s' for days, expected one of eq, all or any", operator)
+}
@@ -0,0 +1,213 @@
+package schema
+
+import (
+	"reflect"
+	"slices"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestParseClockTime(t *testing.T) {
+	testCases := map[string]struct {
+		Value    string
+		Expected int
+		Invalid  bool
+	}{
+		"Morning":    {Value: "10:00am", Expected: 600},
+		"Afternoon":  {Value: "2:30 PM", Expected: 870},
+		"Noon":       {Value: "12:00pm", Expected: 720},
+		"Midnight":   {Value: "12:15am", Expected: 15},
+		"HourOnly":   {Value: "9am", Expected: 540},
+		"Periods":    {Value: "4:00 p.m.", Expected: 960},
+		"TwentyFour": {Value: "14:30", Expected: 870},
+		"Empty":      {Value: "", Invalid: true},
+		"BareHour":   {Value: "14", Invalid: true},
+		"BadHour":    {Value: "13:00pm", Invalid: true},
+		"BadMinutes": {Value: "10:5am", Invalid: true},
+		"OutOfRange": {Value: "25:00", Invalid: true},
+		"NotATime":   {Value: "TBA", Invalid: true},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			result, err := ParseClockTime(tc.Value)
+			if tc.Invalid {
+				if err == nil {
+					t.Errorf("Expected an error, got %d", result)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if result != tc.Expected {
+				t.Errorf("Expected %d, got %d", tc.Expected, result)
+			}
+			if round, _ := ParseClockTime(FormatClockTime(result)); round != result {
+				t.Errorf("Expected %s to round trip, got %d", FormatClockTime(result), round)
+			}
+		})
+	}
+}
+
+func TestParseWeekday(t *testing.T) {
+	testCases := map[string]struct {
+		Value    string
+		Expected time.Weekday
+		Invalid  bool
+	}{
+		"Full":        {Value: "Monday", Expected: time.Monday},
+		"Abbreviated": {Value: "thurs", Expected: time.Thursday},
+		"Padded":      {Value: " SATURDAY ", Expected: time.Saturday},
+		"Unknown":     {Value: "Someday", Invalid: true},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			result, ok := ParseWeekday(tc.Value)
+			if ok == tc.Invalid {
+				t.Fatalf("Expected ok to be %t", !tc.Invalid)
+			}
+			if ok && result != tc.Expected {
+				t.Errorf("Expected %s, got %s", tc.Expected, result)
+			}
+		})
+	}
+}
+
+func TestParseWeekdays(t *testing.T) {
+	testCases := map[string]struct {
+		Value    string
+		Expected Weekdays
+		Invalid  bool
+	}{
+		"Letters":   {Value: "TR", Expected: NewWeekdays(time.Tuesday, time.Thursday)},
+		"Lower":     {Value: "mwf", Expected: NewWeekdays(time.Monday, time.Wednesday, time.Friday)},
+		"Names":     {Value: "Tuesday, Thursday", Expected: NewWeekdays(time.Tuesday, time.Thursday)},
+		"OneName":   {Value: "Saturday", Expected: NewWeekdays(time.Saturday)},
+		"Bitset":    {Value: "20", Expected: NewWeekdays(time.Tuesday, time.Thursday)},
+		"BadBits":   {Value: "200", Invalid: true},
+		"BadLetter": {Value: "MX", Invalid: true},
+		"BadName":   {Value: "Monday,Someday", Invalid: true},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			result, err := ParseWeekdays(tc.Value)
+			if tc.Invalid {
+				if err == nil {
+					t.Errorf("Expected an error, got %s", result)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if result != tc.Expected {
+				t.Errorf("Expected %s, got %s", tc.Expected, result)
+			}
+		})
+	}
+
+	if letters := NewWeekdays(time.Friday, time.Monday, time.Wednesday).String(); letters != "MWF" {
+		t.Errorf("Expected MWF, got %s", letters)
+	}
+}
+
+func TestMeetingComputedOnDecode(t *testing.T) {
+	data, err := bson.Marshal(bson.M{"meetings": bson.A{
+		bson.M{"meeting_days": bson.A{"Tuesday", "Thursday"}, "start_time": "1:00pm", "end_time": "2:15pm"},
+		bson.M{"meeting_days": bson.A{}, "start_time": "", "end_time": ""},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var section Section
+	if err = bson.Unmarshal(data, &section); err != nil {
+		t.Fatal(err)
+	}
+
+	meeting := section.Meetings[0]
+	if meeting.Start_minutes == nil || *meeting.Start_minutes != 780 || meeting.Start_time_24h != "13:00" {
+		t.Errorf("Expected a start of 780 minutes (13:00), got %v (%s)", meeting.Start_minutes, meeting.Start_time_24h)
+	}
+	if meeting.End_minutes == nil || *meeting.End_minutes != 855 || meeting.End_time_24h != "14:15" {
+		t.Errorf("Expected an end of 855 minutes (14:15), got %v (%s)", meeting.End_minutes, meeting.End_time_24h)
+	}
+	if meeting.Days != NewWeekdays(time.Tuesday, time.Thursday) {
+		t.Errorf("Expected TR, got %s", meeting.Days)
+	}
+
+	unscheduled := section.Meetings[1]
+	if unscheduled.Start_minutes != nil || unscheduled.End_time_24h != "" || unscheduled.Days != 0 {
+		t.Errorf("Expected no computed times for an unscheduled meeting, got %+v", unscheduled)
+	}
+}
+
+func TestFilterQueryDerived(t *testing.T) {
+	query, err := FilterQuery[Section](map[string][]string{
+		"meetings.start_minutes[gte]": {"13:00"},
+		"meetings.days":               {"TR"},
+		"section_number":              {"001"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if query["section_number"] != "001" {
+		t.Errorf("Expected stored fields to be matched directly, got %v", query)
+	}
+
+	// Both computed fields must match the same meeting
+	expr, ok := query["$expr"].(bson.M)
+	if !ok {
+		t.Fatalf("Expected an $expr, got %v", query)
+	}
+	mapped := expr["$anyElementTrue"].(bson.A)[0].(bson.M)["$map"].(bson.M)
+	conditions := mapped["in"].(bson.M)["$and"].(bson.A)
+	if len(conditions) != 2 {
+		t.Fatalf("Expected 2 conditions on each meeting, got %d", len(conditions))
+	}
+
+	start, err := clockTimeFilter("$$element.start_time", "gte", "13:00")
+	if err != nil {
+		t.Fatal(err)
+	}
+	days, err := weekdaysFilter("$$element.meeting_days", "eq", "TR")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.ContainsFunc(conditions, func(condition any) bool { return reflect.DeepEqual(condition, start) }) ||
+		!slices.ContainsFunc(conditions, func(condition any) bool { return reflect.DeepEqual(condition, days) }) {
+		t.Errorf("Expected the start time and days conditions, got %v", conditions)
+	}
+
+	// The stored fields are read from the meeting being mapped
+	clock := start["$let"].(bson.M)["vars"].(bson.M)["minutes"].(bson.M)["$let"].(bson.M)["vars"].(bson.M)["time"].(bson.M)
+	if path := clock["$toLower"].(bson.M)["$trim"].(bson.M)["input"].(bson.M)["$ifNull"].(bson.A)[0]; path != "$$element.start_time" {
+		t.Errorf("Expected the start time to be read from $$element.start_time, got %v", path)
+	}
+	stored := days["$setEquals"].(bson.A)[0].(bson.M)["$setUnion"].(bson.A)[0].(bson.M)["$map"].(bson.M)
+	if path := stored["input"].(bson.M)["$ifNull"].(bson.A)[0]; path != "$$element.meeting_days" {
+		t.Errorf("Expected the days to be read from $$element.meeting_days, got %v", path)
+	}
+	if mapped["as"] != "element" {
+		t.Errorf("Expected each meeting to be mapped as element, got %v", mapped["as"])
+	}
+
+	failing := map[string]map[string][]string{
+		"Operator on stored field": {"section_number[gte]": {"001"}},
+		"Invalid time":             {"meetings.start_minutes[gte]": {"noon"}},
+		"Invalid operator":         {"meetings.start_minutes[all]": {"13:00"}},
+		"Invalid days":             {"meetings.days[any]": {"XYZ"}},
+	}
+	for name, values := range failing {
+		t.Run(name, func(t *testing.T) {
+			if _, err := FilterQuery[Section](values); err == nil {
+				t.Errorf("Expected an error")
+			}
+		})
+	}
+}
@@ -43,6 +43,34 @@ type BasicCourse struct {
 	Catalog_year   string             `bson:"catalog_year" json:"catalog_year" queryable:""`
 }
 
+// A course similar to another, with what the two have in common
+type SimilarCourse struct {
+	Course               BasicCourse `json:"course"`
+	Score                float64     `json:"score"`                // from 0 to 1
+	Text_similarity      float64     `json:"text_similarity"`      // cosine similarity of the TF-IDF vectors of the titles and descriptions
+	Shared_prerequisites []string    `json:"shared_prerequisites"` // codes of the courses both require (e.g. CS 2305)
+	Same_class_level     bool        `json:"same_class_level"`
+	Shared_core_flags    []string    `json:"shared_core_flags"` // core flags of sections of both courses
+}
+
+// Explicit mapping between two course codes that refer to the same course (e.g. after a renumbering or a cross-listing
+// that can't be told from the catalog). Aliases are stored in the optional course_aliases collection and maintained by hand,
+// one document per pair of codes, which apply in either direction and to every catalog year (e.g. "CS 4V98" and "SE 4V98").
+type CourseAlias struct {
+	Id             primitive.ObjectID `bson:"_id" json:"_id"`
+	Subject_prefix string             `bson:"subject_prefix" json:"subject_prefix"`
+	Course_number  string             `bson:"course_number" json:"course_number"`
+	Alias_prefix   string             `bson:"alias_prefix" json:"alias_prefix"`
+	Alias_number   string             `bson:"alias_number" json:"alias_number"`
+}
+
+// A course considered equivalent to another, along with the relation that made it equivalent
+type EquivalentCourse struct {
+	BasicCourse            `bson:",inline" json:",inline"`
+	Internal_course_number string `bson:"internal_course_number" json:"internal_course_number"`
+	Relation               string `bson:"relation" json:"relation"`
+}
+
 type AcademicSession struct {
 	Name       string    `bson:"name" json:"name" queryable:""`
 	Start_date time.Time `bson:"start_date" json:"start_date" queryable:""`
@@ -70,6 +98,13 @@ type Meeting struct {
 	End_time     string    `bson:"end_time" json:"end_time" queryable:""`
 	Modality     string    `bson:"modality" json:"modality" queryable:""`
 	Location     Location  `bson:"location" json:"location" queryable:""`
+
+	// Computed from the fields above when decoded, see ComputeTimes
+	Start_minutes  *int     `bson:"-" json:"start_minutes" queryable:"derived"` // minutes since midnight, null if start_time can't be parsed
+	End_minutes    *int     `bson:"-" json:"end_minutes" queryable:"derived"`
+	Start_time_24h string   `bson:"-" json:"start_time_24h" queryable:"derived"` // e.g. "14:30", empty if start_time can't be parsed
+	End_time_24h   string   `bson:"-" json:"end_time_24h" queryable:"derived"`
+	Days           Weekdays `bson:"-" json:"days" queryable:"derived"` // bit n is set when the meeting is on time.Weekday(n)
 }
 
 type Section struct {
@@ -91,6 +126,77 @@ type Section struct {
 	Course_details        *[]BasicCourse         `bson:"course_details,omitempty" json:"course_details,omitempty"`       // only shows if course_details was set by the endpoint
 }
 
+// Request body for schedule endpoints operating on a set of sections
+type ScheduleSectionsBody struct {
+	Sections []primitive.ObjectID `json:"sections"`
+}
+
+// Two sections whose meetings overlap, with the days, times and dates on which they overlap
+type ScheduleConflict struct {
+	Sections   [2]primitive.ObjectID `bson:"sections" json:"sections"`
+	Days       []string              `bson:"days" json:"days"`
+	Start_time string                `bson:"start_time" json:"start_time"`
+	End_time   string                `bson:"end_time" json:"end_time"`
+	Start_date time.Time             `bson:"start_date" json:"start_date"`
+	End_date   time.Time             `bson:"end_date" json:"end_date"`
+}
+
+// Walking from one section to another whose meeting follows it on the same days
+type ScheduleTransition struct {
+	Sections     [2]primitive.ObjectID `bson:"sections" json:"sections"` // the earlier section, then the later one
+	Days         []string              `bson:"days" json:"days"`
+	From         Location              `bson:"from" json:"from"`
+	To           Location              `bson:"to" json:"to"`
+	End_time     string                `bson:"end_time" json:"end_time"`     // when the earlier meeting ends
+	Start_time   string                `bson:"start_time" json:"start_time"` // when the later meeting starts
+	Gap_minutes  int                   `bson:"gap_minutes" json:"gap_minutes"`
+	Walk_minutes *int                  `bson:"walk_minutes" json:"walk_minutes"` // nil if the walk can't be estimated
+	Distance     *float64              `bson:"distance" json:"distance"`         // meters, nil if either building's location is unknown
+	Status       string                `bson:"status" json:"status"`             // one of ok, tight, infeasible or unknown
+}
+
+// Constraints on the sections of a generated schedule, either required or preferred
+type ScheduleConstraints struct {
+	Earliest_start   string                          `json:"earliest_start"` // e.g. "10:00am"
+	Latest_end       string                          `json:"latest_end"`     // e.g. "5:00pm"
+	Days_off         []string                        `json:"days_off"`
+	Modalities       []string                        `json:"modalities"`       // matched against instruction_mode, e.g. "Face-to-Face"
+	Professors       map[string][]primitive.ObjectID `json:"professors"`       // professors of each course by course code, e.g. "CS 3345"
+	Avoid_professors []primitive.ObjectID            `json:"avoid_professors"` // professors to avoid in every course
+}
+
+// Request body for generating schedules
+type ScheduleGenerateBody struct {
+	Academic_session string              `json:"academic_session"`
+	Courses          []string            `json:"courses"` // course codes, e.g. "CS 3345"
+	Require          ScheduleConstraints `json:"require"`
+	Prefer           ScheduleConstraints `json:"prefer"`
+	Limit            int                 `json:"limit"`
+}
+
+// A section chosen for a course of a generated schedule
+type ScheduledSection struct {
+	Course           string               `bson:"course" json:"course"`
+	Id               primitive.ObjectID   `bson:"_id" json:"_id"`
+	Section_number   string               `bson:"section_number" json:"section_number"`
+	Professors       []primitive.ObjectID `bson:"professors" json:"professors"`
+	Instruction_mode string               `bson:"instruction_mode" json:"instruction_mode"`
+	Meetings         []Meeting            `bson:"meetings" json:"meetings"`
+}
+
+// A conflict-free set of sections, one per requested course, scored from 0 to 1 by how well it satisfies the preferences
+type GeneratedSchedule struct {
+	Score    float64            `bson:"score" json:"score"`
+	Sections []ScheduledSection `bson:"sections" json:"sections"`
+}
+
+type GeneratedSchedules struct {
+	Schedules     []GeneratedSchedule `bson:"schedules" json:"schedules"`
+	Found         int                 `bson:"found" json:"found"`                 // number of conflict-free schedules found before ranking
+	Truncated     bool                `bson:"truncated" json:"truncated"`         // whether the search stopped early and more schedules may exist
+	Unschedulable []string            `bson:"unschedulable" json:"unschedulable"` // courses with no section satisfying the requirements
+}
+
 type Professor struct {
 	Id           primitive.ObjectID   `bson:"_id" json:"_id"`
 	First_name   string               `bson:"first_name" json:"first_name" queryable:""`
@@ -115,6 +221,13 @@ type BasicProfessor struct {
 	Office_hours []Meeting          `bson:"office_hours" json:"office_hours"`
 }
 
+// A professor found by name search, with how closely their name matches
+type ProfessorCandidate struct {
+	Professor Professor `json:"professor"`
+	Score     float64   `json:"score"` // from 0 to 1
+	Match     string    `json:"match"` // exact, nickname, prefix or fuzzy, the loosest way any word of the query matched
+}
+
 type Organization struct {
 	Id             primitive.ObjectID `bson:"_id" json:"_id"`
 	Title          string             `bson:"title" json:"title"`
@@ -214,6 +327,11 @@ type AstraEvent struct {
 	NotAllowedUsageMask *float64 `bson:"not_allowed_usage_mask" json:"not_allowed_usage_mask"`
 	UsageColor          *string  `bson:"usage_color" json:"usage_color"`
 	Capacity            *float64 `bson:"capacity" json:"capacity"`
+
+	// Decoded from the fields above
+	State            AstraState `bson:"-" json:"state"`             // unknown unless the state name is configured, see SetAstraStateNames
+	DisallowedUsages []string   `bson:"-" json:"disallowed_usages"` // usage_<bit> unless the bit is named, see SetAstraUsageNames
+	Color            *string    `bson:"-" json:"color"`             // usage color as #rrggbb, nil if it isn't a hex color
 }
 type MazevoEvent struct {
 	EventName         *string  `bson:"eventName" json:"eventName"`
@@ -225,6 +343,12 @@ type MazevoEvent struct {
 	TeardownMinutes   *float64 `bson:"teardownMinutes" json:"teardownMinutes"`
 	StatusDescription *string  `bson:"statusDescription" json:"statusDescription"`
 	StatusColor       *string  `bson:"statusColor" json:"statusColor"`
+
+	// Computed from the fields above, nil if the timestamps are missing or invalid
+	Start         *time.Time `bson:"-" json:"start"`
+	End           *time.Time `bson:"-" json:"end"`
+	OccupiedStart *time.Time `bson:"-" json:"occupiedStart"` // start less the setup minutes
+	OccupiedEnd   *time.Time `bson:"-" json:"occupiedEnd"`   // end plus the teardown minutes
 }
 
 // Rooms type
@@ -239,7 +363,110 @@ type Room struct {
 	Capacity int    `bson:"capacity" json:"capacity"`
 }
 
-// Map location type
+// The events in a room on each day of a week, Monday first
+type RoomSchedule struct {
+	Building   string            `bson:"building" json:"building"`
+	Room       string            `bson:"room" json:"room"`
+	Week_start string            `bson:"week_start" json:"week_start"` // ISO date of the Monday
+	Week_end   string            `bson:"week_end" json:"week_end"`     // ISO date of the Sunday
+	Days       []RoomScheduleDay `bson:"days" json:"days"`
+}
+
+type RoomScheduleDay struct {
+	Date    string         `bson:"date" json:"date"`
+	Weekday string         `bson:"weekday" json:"weekday"`
+	Events  []UnifiedEvent `bson:"events" json:"events"`
+}
+
+// A building along with how far it is from a point
+type NearbyBuilding struct {
+	Building string  `bson:"building" json:"building"`
+	Lat      float64 `bson:"lat" json:"lat"`
+	Lng      float64 `bson:"lng" json:"lng"`
+	Distance float64 `bson:"distance" json:"distance"` // meters
+	Rooms    []Room  `bson:"rooms" json:"rooms"`
+}
+
+// Estimated walks between every pair of buildings, indexed in the order of the buildings
+type WalkTimeMatrix struct {
+	Buildings []string     `bson:"buildings" json:"buildings"`
+	Minutes   [][]*int     `bson:"minutes" json:"minutes"`     // nil if the walk can't be estimated
+	Distances [][]*float64 `bson:"distances" json:"distances"` // meters, nil if either building's location is unknown
+}
+
+// An event from any of the event sources in a common shape
+type UnifiedEvent struct {
+	Id        string    `bson:"_id" json:"_id"` // ID of the section or Comet Calendar event, empty for sources without IDs
+	Title     string    `bson:"title" json:"title"`
+	Source    string    `bson:"source" json:"source"` // one of coursebook, astra, mazevo or comet_calendar
+	Start     time.Time `bson:"start" json:"start"`
+	End       time.Time `bson:"end" json:"end"`
+	Building  string    `bson:"building" json:"building"`
+	Room      string    `bson:"room" json:"room"`
+	Organizer string    `bson:"organizer" json:"organizer"`
+	Link      string    `bson:"link" json:"link"`
+	State     string    `bson:"state" json:"state"` // state of Astra bookings and status of Mazevo events (e.g. cancelled), empty for other sources
+
+	// When the room is occupied, which includes setup and teardown for Mazevo events.
+	// Cancelled and denied events are listed with their own span but don't occupy their rooms.
+	Occupied_start time.Time `bson:"occupied_start" json:"occupied_start"`
+	Occupied_end   time.Time `bson:"occupied_end" json:"occupied_end"`
+}
+
+// A span of time, such as a stretch during which a room is free
+type TimeRange struct {
+	Start time.Time `bson:"start" json:"start"`
+	End   time.Time `bson:"end" json:"end"`
+}
+
+// A room with no events during part of a requested time window
+type AvailableRoom struct {
+	Building string      `bson:"building" json:"building"`
+	Room     string      `bson:"room" json:"room"`
+	Capacity int         `bson:"capacity" json:"capacity"`
+	Free     []TimeRange `bson:"free" json:"free"` // free stretches within the window, each at least the requested duration
+}
+
+// Share of an hour of the week that rooms are booked, such as Monday 10:00-11:00
+type HourUtilization struct {
+	Weekday     string  `bson:"weekday" json:"weekday"`
+	Hour        int     `bson:"hour" json:"hour"`               // 0-23
+	Utilization float64 `bson:"utilization" json:"utilization"` // 0-1
+}
+
+type RoomUtilization struct {
+	Room         string  `bson:"room" json:"room"`
+	Capacity     int     `bson:"capacity" json:"capacity"`
+	Booked_hours float64 `bson:"booked_hours" json:"booked_hours"` // during opening hours
+	Utilization  float64 `bson:"utilization" json:"utilization"`   // share of opening hours booked, 0-1
+}
+
+type BuildingUtilization struct {
+	Building     string               `bson:"building" json:"building"`
+	Booked_hours float64              `bson:"booked_hours" json:"booked_hours"`
+	Utilization  float64              `bson:"utilization" json:"utilization"`
+	Heatmap      map[string][]float64 `bson:"heatmap" json:"heatmap"` // share of each hour booked by weekday, 24 values per weekday in the range
+	Peak_hours   []HourUtilization    `bson:"peak_hours" json:"peak_hours"`
+	Idle_rooms   []string             `bson:"idle_rooms" json:"idle_rooms"` // rooms never booked during opening hours
+	Rooms        []RoomUtilization    `bson:"rooms" json:"rooms"`
+}
+
+// Utilization of rooms over a date range, weighted by capacity if requested
+type RoomsUtilization struct {
+	From         string                `bson:"from" json:"from"`
+	To           string                `bson:"to" json:"to"`
+	Open         string                `bson:"open" json:"open"`
+	Close        string                `bson:"close" json:"close"`
+	Weighted     bool                  `bson:"weighted" json:"weighted"`
+	Booked_hours float64               `bson:"booked_hours" json:"booked_hours"`
+	Utilization  float64               `bson:"utilization" json:"utilization"`
+	Heatmap      map[string][]float64  `bson:"heatmap" json:"heatmap"`
+	Peak_hours   []HourUtilization     `bson:"peak_hours" json:"peak_hours"`
+	Buildings    []BuildingUtilization `bson:"buildings" json:"buildings"`
+}
+
+// Map location type. Buildings on the campus map are stored in the optional mapLocations collection,
+// one document per building with its full name, its acronym as used by the rooms collection (e.g. "ECSS") and its coordinates in degrees
 type MapBuilding struct {
 	Name    *string  `bson:"name" json:"name"`
 	Acronym *string  `bson:"acronym" json:"acronym"`
@@ -248,8 +475,26 @@ type MapBuilding struct {
 }
 
 type GradeData struct {
-	Id                string  `bson:"_id" json:"_id"`
-	GradeDistribution [14]int `bson:"grade_distribution" json:"grade_distribution"`
+	Id                string      `bson:"_id" json:"_id"`
+	GradeDistribution [14]int     `bson:"grade_distribution" json:"grade_distribution"`
+	Sections          int         `bson:"sections" json:"-"`
+	Stats             *GradeStats `bson:"-" json:"stats,omitempty"`
+}
+
+// Statistics describing how much confidence a grade distribution warrants
+type GradeStats struct {
+	Sections       int     `bson:"sections" json:"sections"`
+	Sample_size    int     `bson:"sample_size" json:"sample_size"`       // students who received a letter grade
+	Gpa            float64 `bson:"gpa" json:"gpa"`                       // mean grade points of the distribution
+	Standard_error float64 `bson:"standard_error" json:"standard_error"` // standard error of the GPA, 0 with fewer than two graded students
+	Prior_gpa      float64 `bson:"prior_gpa" json:"prior_gpa"`           // mean the GPA is shrunk toward
+	Shrunk_gpa     float64 `bson:"shrunk_gpa" json:"shrunk_gpa"`         // GPA pulled toward the prior, more strongly for small samples
+}
+
+// Overall grade distribution along with its statistics
+type GradeSummary struct {
+	GradeDistribution [14]int    `bson:"grade_distribution" json:"grade_distribution"`
+	Stats             GradeStats `bson:"stats" json:"stats"`
 }
 
 type TypedGradeData struct {
@@ -260,6 +505,25 @@ type TypedGradeData struct {
 	} `bson:"data" json:"data"`
 }
 
+// Grade metrics of a course or professor ranked within its peer group
+type GradeRanking struct {
+	Id             string  `bson:"_id" json:"_id"`
+	Name           string  `bson:"name" json:"name"`
+	Sections       int     `bson:"sections" json:"sections"`
+	Students       int     `bson:"students" json:"students"`
+	Gpa            float64 `bson:"gpa" json:"gpa"`
+	Dfw_rate       float64 `bson:"dfw_rate" json:"dfw_rate"`
+	Gpa_percentile float64 `bson:"gpa_percentile" json:"gpa_percentile"`
+	Dfw_percentile float64 `bson:"dfw_percentile" json:"dfw_percentile"`
+	Rank           int     `bson:"rank" json:"rank"`
+}
+
+type GradeRankings struct {
+	Peer_group       string         `bson:"peer_group" json:"peer_group"`
+	Academic_session string         `bson:"academic_session" json:"academic_session"`
+	Rankings         []GradeRanking `bson:"rankings" json:"rankings"`
+}
+
 // Prefix used for cloud storage bucket names
 const BUCKET_PREFIX = "utdnebula_"
 
@@ -379,6 +643,22 @@ type Club struct {
 	Contacts     []Contact           `json:"contacts"`
 }
 
+// A result of the unified search, scored so that hits of different types can be ranked together
+type SearchHit struct {
+	Type     string  `json:"type"` // course, professor, club, discount or room
+	Id       string  `json:"id"`   // ID of the course, professor, club or discount, or building and room number of the room
+	Title    string  `json:"title"`
+	Subtitle string  `json:"subtitle"`
+	Score    float64 `json:"score"` // relevance from 0 to 1
+}
+
+type SearchResults struct {
+	Query  string         `json:"query"`
+	Hits   []SearchHit    `json:"hits"`   // most relevant first
+	Counts map[string]int `json:"counts"` // number of hits of each type searched
+	Failed []string       `json:"failed"` // types whose search failed and are missing from the hits
+}
+
 // Type for all API responses
 type APIResponse[T any] struct {
 	Status  int    `json:"status"`
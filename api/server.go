@@ -4,8 +4,10 @@ import (
 	"log"
 
 	"github.com/UTDNebula/nebula-api/api/configs"
+	"github.com/UTDNebula/nebula-api/api/controllers"
 	_ "github.com/UTDNebula/nebula-api/api/docs"
 	"github.com/UTDNebula/nebula-api/api/routes"
+	"github.com/UTDNebula/nebula-api/api/schema"
 	"github.com/getsentry/sentry-go"
 	sentrygin "github.com/getsentry/sentry-go/gin"
 	"github.com/gin-gonic/gin"
@@ -48,6 +50,15 @@ func main() {
 	configs.ConnectDB()
 	configs.ConnectClubsDB()
 
+	// Name the Astra states and usages the way this deployment's Astra instance does
+	if err := schema.SetAstraStateNames(configs.GetEnvAstraStates()); err != nil {
+		log.Fatalf("Error loading 'ASTRA_STATES' from the .env file: %v", err)
+	}
+	schema.SetAstraUsageNames(configs.GetEnvAstraUsages())
+
+	// Build the in-memory indexes and check for Atlas Search before they're first needed
+	controllers.WarmIndexes()
+
 	// Set up Sentry
 	if err := sentry.Init(sentry.ClientOptions{
 		Dsn:              "https://530f8e39f757b71ab26ad1aa12e17a4d@o4504918397353984.ingest.us.sentry.io/4509397160493056",
@@ -82,12 +93,16 @@ func main() {
 	routes.AutocompleteRoute(router)
 	routes.StorageRoute(router)
 	routes.RoomsRoute(router)
+	routes.BuildingsRoute(router)
 	routes.EventsRoute(router)
 	routes.AstraRoute(router)
 	routes.MazevoRoute(router)
 	routes.CalendarRoute(router)
+	routes.TimelineRoute(router)
 	routes.ClubRoute(router)
 	routes.DiscountRoutes(router)
+	routes.ScheduleRoute(router)
+	routes.SearchRoute(router)
 
 	// Retrieve the port string to serve traffic on
 	portString := configs.GetPortString()
@@ -99,8 +114,9 @@ func main() {
 func CORS(c *gin.Context) {
 	c.Writer.Header().Set("Access-Control-Allow-Origin", "*")
 	c.Writer.Header().Set("Access-Control-Allow-Credentials", "true")
-	c.Writer.Header().Set("Access-Control-Allow-Headers", "Accept, x-api-key, Origin, Content-type, Authorization, sentry-trace, baggage")
-	c.Writer.Header().Set("Access-Control-Allow-Methods", "OPTIONS, GET")
+	c.Writer.Header().Set("Access-Control-Allow-Headers", "Accept, x-api-key, Origin, Content-type, Authorization, sentry-trace, baggage, If-None-Match")
+	c.Writer.Header().Set("Access-Control-Expose-Headers", "ETag")
+	c.Writer.Header().Set("Access-Control-Allow-Methods", "OPTIONS, GET, POST")
 
 	if c.Request.Method == "OPTIONS" {
 		c.IndentedJSON(204, "")